@@ -2,6 +2,7 @@ package ast
 
 import (
 	"bytes"
+	"math/big"
 	"strings"
 
 	"github.com/Suryansh-23/amrit/token"
@@ -43,9 +44,10 @@ func (p *Program) String() string {
 }
 
 type LetStatement struct {
-	Token token.Token // the token.LET token
-	Name  *Identifier
-	Value Expression
+	Token   token.Token // the token.LET token
+	Name    *Identifier
+	Pattern Expression // set instead of Name when destructuring, e.g. [a, b] or {"k": v}
+	Value   Expression
 }
 
 func (ls *LetStatement) statementNode()       {}
@@ -54,7 +56,11 @@ func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 
 	out.WriteString(ls.TokenLiteral() + " ")
-	out.WriteString(ls.Name.String())
+	if ls.Pattern != nil {
+		out.WriteString(ls.Pattern.String())
+	} else {
+		out.WriteString(ls.Name.String())
+	}
 	out.WriteString(" = ")
 
 	if ls.Value != nil {
@@ -91,6 +97,117 @@ func (cas *CompoundAssignmentStatement) String() string {
 	return out.String()
 }
 
+// dhancha Vyakti { naam, umar }
+type StructStatement struct {
+	Token   token.Token // the 'dhancha' token
+	Name    *Identifier
+	Fields  []*Identifier
+	Methods []*FunctionLiteral
+}
+
+func (ss *StructStatement) statementNode()       {}
+func (ss *StructStatement) TokenLiteral() string { return ss.Token.Literal }
+func (ss *StructStatement) String() string {
+	var out bytes.Buffer
+
+	fields := []string{}
+	for _, f := range ss.Fields {
+		fields = append(fields, f.String())
+	}
+
+	out.WriteString("dhancha ")
+	out.WriteString(ss.Name.String())
+	out.WriteString(" { ")
+	out.WriteString(strings.Join(fields, ", "))
+	for _, m := range ss.Methods {
+		out.WriteString(" " + m.String())
+	}
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+// varg Kutta(Janwar) { karya naya(naam) { ... } karya bolo() { ... } }
+type ClassStatement struct {
+	Token   token.Token // the 'varg' token
+	Name    *Identifier
+	Parent  *Identifier // nil when the class has no parent
+	Methods []*FunctionLiteral
+}
+
+func (cs *ClassStatement) statementNode()       {}
+func (cs *ClassStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ClassStatement) String() string {
+	var out bytes.Buffer
+
+	methods := []string{}
+	for _, m := range cs.Methods {
+		methods = append(methods, m.String())
+	}
+
+	out.WriteString("varg ")
+	out.WriteString(cs.Name.String())
+	if cs.Parent != nil {
+		out.WriteString("(" + cs.Parent.String() + ")")
+	}
+	out.WriteString(" { ")
+	out.WriteString(strings.Join(methods, " "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+// ganana Rang { Lal, Hara, Neela }
+type EnumStatement struct {
+	Token    token.Token // the 'ganana' token
+	Name     *Identifier
+	Variants []*Identifier
+}
+
+func (es *EnumStatement) statementNode()       {}
+func (es *EnumStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *EnumStatement) String() string {
+	var out bytes.Buffer
+
+	variants := []string{}
+	for _, v := range es.Variants {
+		variants = append(variants, v.String())
+	}
+
+	out.WriteString("ganana ")
+	out.WriteString(es.Name.String())
+	out.WriteString(" { ")
+	out.WriteString(strings.Join(variants, ", "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+// p.naam = x, or a compound assignment such as p.umar += 1
+type MemberAssignStatement struct {
+	Token    token.Token // the assignment operator token
+	Target   *MemberExpression
+	Operator string
+	Value    Expression
+}
+
+func (mas *MemberAssignStatement) statementNode()       {}
+func (mas *MemberAssignStatement) TokenLiteral() string { return mas.Token.Literal }
+func (mas *MemberAssignStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(mas.Target.String())
+	out.WriteString(" " + mas.Operator + " ")
+
+	if mas.Value != nil {
+		out.WriteString(mas.Value.String())
+	}
+
+	out.WriteString("|")
+
+	return out.String()
+}
+
 type Identifier struct {
 	Token token.Token // the token.IDENT token
 	Value string
@@ -121,6 +238,19 @@ func (rs *ReturnStatement) String() string {
 	return out.String()
 }
 
+// upaj <value>, which hands value to whoever resumed the generator and
+// suspends it until it is resumed again
+type YieldStatement struct {
+	Token token.Token // the 'upaj' token
+	Value Expression
+}
+
+func (ys *YieldStatement) statementNode()       {}
+func (ys *YieldStatement) TokenLiteral() string { return ys.Token.Literal }
+func (ys *YieldStatement) String() string {
+	return ys.TokenLiteral() + " " + ys.Value.String() + "|"
+}
+
 type ExpressionStatement struct {
 	Token      token.Token
 	Expression Expression
@@ -144,6 +274,26 @@ func (il *IntegerLiteral) expressionNode()      {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
 func (il *IntegerLiteral) String() string       { return il.Token.Literal }
 
+// an integer literal too large for an int64
+type BigIntegerLiteral struct {
+	Token token.Token
+	Value *big.Int
+}
+
+func (bl *BigIntegerLiteral) expressionNode()      {}
+func (bl *BigIntegerLiteral) TokenLiteral() string { return bl.Token.Literal }
+func (bl *BigIntegerLiteral) String() string       { return bl.Token.Literal }
+
+// 12.50d
+type DecimalLiteral struct {
+	Token token.Token
+	Value string // the digits without their d suffix, e.g. 12.50
+}
+
+func (dl *DecimalLiteral) expressionNode()      {}
+func (dl *DecimalLiteral) TokenLiteral() string { return dl.Token.Literal }
+func (dl *DecimalLiteral) String() string       { return dl.Token.Literal }
+
 type StringLiteral struct {
 	Token token.Token // STRING Type
 	Value string      // A std GO string value
@@ -153,6 +303,15 @@ func (sl *StringLiteral) expressionNode()      {}
 func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
 func (sl *StringLiteral) String() string       { return sl.Token.Literal }
 
+type CharLiteral struct {
+	Token token.Token // CHAR Type
+	Value rune
+}
+
+func (cl *CharLiteral) expressionNode()      {}
+func (cl *CharLiteral) TokenLiteral() string { return cl.Token.Literal }
+func (cl *CharLiteral) String() string       { return "'" + cl.Token.Literal + "'" }
+
 type PrefixExpression struct {
 	Token    token.Token // The prefix token, e.g. !
 	Operator string
@@ -246,6 +405,48 @@ func (ie *IfExpression) String() string {
 	return out.String()
 }
 
+// milao (x) { Rang.Lal => "ruko", _ => "chalo" }
+type MatchExpression struct {
+	Token   token.Token // the 'milao' token
+	Subject Expression
+	Arms    []*MatchArm
+}
+
+func (me *MatchExpression) expressionNode()      {}
+func (me *MatchExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MatchExpression) String() string {
+	var out bytes.Buffer
+
+	arms := []string{}
+	for _, arm := range me.Arms {
+		arms = append(arms, arm.String())
+	}
+
+	out.WriteString("milao (")
+	out.WriteString(me.Subject.String())
+	out.WriteString(") { ")
+	out.WriteString(strings.Join(arms, ", "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+// one pattern => body arm of a milao; a nil Pattern is the _ wildcard
+type MatchArm struct {
+	Token   token.Token // the '=>' token
+	Pattern Expression
+	Body    *BlockStatement
+}
+
+func (ma *MatchArm) String() string {
+	pattern := "_"
+	if ma.Pattern != nil {
+		pattern = ma.Pattern.String()
+	}
+
+	return pattern + " => " + ma.Body.String()
+}
+
 type WhileExpression struct {
 	Token     token.Token // The 'while' token
 	Condition Expression
@@ -266,10 +467,92 @@ func (we *WhileExpression) String() string {
 	return out.String()
 }
 
+// har (x mein iterable) { }
+type ForEachExpression struct {
+	Token    token.Token // The 'har' token
+	Variable Expression  // an identifier or a destructuring pattern
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fe *ForEachExpression) expressionNode()      {}
+func (fe *ForEachExpression) TokenLiteral() string { return fe.Token.Literal }
+func (fe *ForEachExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("har ")
+	out.WriteString(fe.Variable.String())
+	out.WriteString(" mein ")
+	out.WriteString(fe.Iterable.String())
+	out.WriteString(" { ")
+	out.WriteString(fe.Body.String())
+	out.WriteString(" } ")
+
+	return out.String()
+}
+
+// koshish { } pakdo (e) { } aakhir { }
+type TryExpression struct {
+	Token      token.Token // The 'koshish' token
+	Block      *BlockStatement
+	CatchParam *Identifier     // nil when the caught error isn't bound
+	Catch      *BlockStatement // nil without a pakdo block
+	Finally    *BlockStatement // nil without an aakhir block
+}
+
+func (te *TryExpression) expressionNode()      {}
+func (te *TryExpression) TokenLiteral() string { return te.Token.Literal }
+func (te *TryExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("koshish { ")
+	out.WriteString(te.Block.String())
+	out.WriteString(" } ")
+
+	if te.Catch != nil {
+		out.WriteString("pakdo ")
+		if te.CatchParam != nil {
+			out.WriteString("(" + te.CatchParam.String() + ") ")
+		}
+		out.WriteString("{ ")
+		out.WriteString(te.Catch.String())
+		out.WriteString(" } ")
+	}
+
+	if te.Finally != nil {
+		out.WriteString("aakhir { ")
+		out.WriteString(te.Finally.String())
+		out.WriteString(" } ")
+	}
+
+	return out.String()
+}
+
+type ThrowStatement struct {
+	Token token.Token // The 'phenko' token
+	Value Expression
+}
+
+func (ts *ThrowStatement) statementNode()       {}
+func (ts *ThrowStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *ThrowStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ts.TokenLiteral() + " ")
+	if ts.Value != nil {
+		out.WriteString(ts.Value.String())
+	}
+	out.WriteString("|")
+
+	return out.String()
+}
+
 type FunctionLiteral struct {
-	Token      token.Token // The 'karya' token
-	Parameters []*Identifier
+	Token      token.Token  // The 'karya' token, or the => of an arrow function
+	Name       *Identifier  // nil for anonymous functions
+	Parameters []Expression // Identifiers, destructuring patterns, DefaultParameters or a trailing ...rest
 	Body       *BlockStatement
+	Generator  bool // whether Body has an upaj of its own, so calls return a generator
 }
 
 func (fl *FunctionLiteral) expressionNode()      {}
@@ -282,7 +565,18 @@ func (fl *FunctionLiteral) String() string {
 		params = append(params, p.String())
 	}
 
+	if fl.Token.Type == token.ARROW {
+		out.WriteString("(")
+		out.WriteString(strings.Join(params, ", "))
+		out.WriteString(") => ")
+		out.WriteString(fl.Body.String())
+		return out.String()
+	}
+
 	out.WriteString(fl.TokenLiteral())
+	if fl.Name != nil {
+		out.WriteString(" " + fl.Name.String())
+	}
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(") ")
@@ -291,6 +585,30 @@ func (fl *FunctionLiteral) String() string {
 	return out.String()
 }
 
+// karya <name>(<parameters>) { <body> }
+type FunctionStatement struct {
+	Token    token.Token // The 'karya' token
+	Name     *Identifier
+	Function *FunctionLiteral
+}
+
+func (fs *FunctionStatement) statementNode()       {}
+func (fs *FunctionStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *FunctionStatement) String() string       { return fs.Function.String() }
+
+// <parameter> = <expression>
+type DefaultParameter struct {
+	Token   token.Token // the '=' token
+	Name    Expression  // Identifier or destructuring pattern
+	Default Expression
+}
+
+func (dp *DefaultParameter) expressionNode()      {}
+func (dp *DefaultParameter) TokenLiteral() string { return dp.Token.Literal }
+func (dp *DefaultParameter) String() string {
+	return dp.Name.String() + " = " + dp.Default.String()
+}
+
 type CallExpression struct {
 	Token     token.Token // The '(' token
 	Function  Expression  // Identifier or FunctionLiteral
@@ -315,6 +633,16 @@ func (ce *CallExpression) String() string {
 	return out.String()
 }
 
+// ...<expression>
+type SpreadExpression struct {
+	Token token.Token // the '...' token
+	Value Expression
+}
+
+func (se *SpreadExpression) expressionNode()      {}
+func (se *SpreadExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SpreadExpression) String() string       { return se.Token.Literal + se.Value.String() }
+
 type ArrayLiteral struct {
 	Token    token.Token  // the '[' token
 	Elements []Expression // the comma separated values
@@ -359,10 +687,32 @@ func (ie *IndexExpression) String() string {
 	return out.String()
 }
 
+// p.naam
+type MemberExpression struct {
+	Token    token.Token // the '.' token
+	Object   Expression
+	Property *Identifier
+}
+
+func (me *MemberExpression) expressionNode()      {}
+func (me *MemberExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MemberExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(me.Object.String())
+	out.WriteString(".")
+	out.WriteString(me.Property.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
 type SliceExpression struct {
 	Token token.Token // the ':' token
-	Left  Expression
-	Right Expression
+	Left  Expression  // nil when the start is left open: a[:3]
+	Right Expression  // nil when the end is left open: a[2:]
+	Step  Expression  // nil without a second ':' or when it is left open
 }
 
 func (se *SliceExpression) expressionNode()      {}
@@ -371,9 +721,16 @@ func (se *SliceExpression) String() string {
 	var out bytes.Buffer
 
 	out.WriteString("(")
-	out.WriteString(se.Left.String() + " ")
+	if se.Left != nil {
+		out.WriteString(se.Left.String() + " ")
+	}
 	out.WriteString(se.Token.Literal)
-	out.WriteString(" " + se.Right.String())
+	if se.Right != nil {
+		out.WriteString(" " + se.Right.String())
+	}
+	if se.Step != nil {
+		out.WriteString(" " + se.Token.Literal + " " + se.Step.String())
+	}
 	out.WriteString(")")
 
 	return out.String()
@@ -402,7 +759,13 @@ func (sae *SliceArrayExpression) String() string {
 
 type HashLiteral struct {
 	Token token.Token // the '{' token
-	Pairs map[Expression]Expression
+	Pairs []HashPair  // in the order they were written
+}
+
+// HashPair is one key: value of a hash literal
+type HashPair struct {
+	Key   Expression
+	Value Expression
 }
 
 func (hl *HashLiteral) expressionNode()      {}
@@ -411,8 +774,8 @@ func (hl *HashLiteral) String() string {
 	var out bytes.Buffer
 
 	pairs := []string{}
-	for key, value := range hl.Pairs {
-		pairs = append(pairs, key.String()+":"+value.String())
+	for _, pair := range hl.Pairs {
+		pairs = append(pairs, pair.Key.String()+":"+pair.Value.String())
 	}
 
 	out.WriteString("{")
@@ -422,17 +785,97 @@ func (hl *HashLiteral) String() string {
 	return out.String()
 }
 
-type Comment struct {
-	Token token.Token // the '//' or '/*' token
-	Value string
+// (1, 2), the one-element (1,) and the empty (), or the bare a, b of
+// labh a, b and mana q, r = ...
+type TupleLiteral struct {
+	Token    token.Token // the '(' token, or the first ',' of a bare tuple
+	Elements []Expression
 }
 
-func (c *Comment) expressionNode()      {}
-func (c *Comment) TokenLiteral() string { return c.Token.Literal }
-func (c *Comment) String() string {
-	if c.Token.Literal == "//" {
-		return "//" + c.Value
+func (tl *TupleLiteral) expressionNode()      {}
+func (tl *TupleLiteral) TokenLiteral() string { return tl.Token.Literal }
+func (tl *TupleLiteral) String() string {
+	elements := []string{}
+	for _, el := range tl.Elements {
+		elements = append(elements, el.String())
+	}
+
+	if len(elements) == 1 {
+		return "(" + elements[0] + ",)"
+	}
+	return "(" + strings.Join(elements, ", ") + ")"
+}
+
+// {1, 2, 3}; an empty {} is always a hash
+type SetLiteral struct {
+	Token    token.Token // the '{' token
+	Elements []Expression
+}
+
+func (sl *SetLiteral) expressionNode()      {}
+func (sl *SetLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *SetLiteral) String() string {
+	elements := []string{}
+	for _, el := range sl.Elements {
+		elements = append(elements, el.String())
+	}
+
+	return "{" + strings.Join(elements, ", ") + "}"
+}
+
+// [x * x har x mein xs agar x > 0] builds an array, {x har x mein xs} a set
+// and {k: v har k mein xs} a hash
+type Comprehension struct {
+	Token   token.Token // the '[' or '{' token
+	Key     Expression  // the key of each pair of a hash, nil for an array or set
+	Value   Expression  // each element, or the value of each pair of a hash
+	Clauses []*ComprehensionClause
+}
+
+func (c *Comprehension) expressionNode()      {}
+func (c *Comprehension) TokenLiteral() string { return c.Token.Literal }
+func (c *Comprehension) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(c.Token.Literal)
+	if c.Key != nil {
+		out.WriteString(c.Key.String() + ": ")
+	}
+	out.WriteString(c.Value.String())
+	for _, clause := range c.Clauses {
+		out.WriteString(" " + clause.String())
+	}
+	if c.Token.Literal == "[" {
+		out.WriteString("]")
 	} else {
-		return "/*" + c.Value + "*/"
+		out.WriteString("}")
 	}
+
+	return out.String()
 }
+
+// har x mein xs, which runs the clauses after it for each element, or agar c,
+// which runs them only when c holds
+type ComprehensionClause struct {
+	Token     token.Token // the 'har' or 'agar' token
+	Variable  Expression  // an identifier or a destructuring pattern, nil for agar
+	Iterable  Expression
+	Condition Expression
+}
+
+func (cc *ComprehensionClause) String() string {
+	if cc.Variable == nil {
+		return "agar " + cc.Condition.String()
+	}
+
+	return "har " + cc.Variable.String() + " mein " + cc.Iterable.String()
+}
+
+type Comment struct {
+	Token token.Token // the '#' or '/*' token
+	Value string      // the comment text, including its delimiters
+}
+
+func (c *Comment) expressionNode()      {}
+func (c *Comment) TokenLiteral() string { return c.Token.Literal }
+func (c *Comment) String() string       { return c.Value }
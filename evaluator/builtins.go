@@ -1,35 +1,50 @@
 package evaluator
 
 import (
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
 	"github.com/Suryansh-23/amrit/object"
 )
 
+// the most decimal places golai rounds to, which keeps the padded value to a
+// few kilobytes
+const maxPlaces = 1 << 12
+
 var builtins = map[string]*object.Builtin{
 	"lambai": {
 		Fn: func(stdout *[]string, args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("wrong number of arguments. got=%d, want=1",
+				return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1",
 					len(args))
 			}
 
 			switch arg := args[0].(type) {
 			case *object.String:
-				return &object.Integer{Value: int64(len(arg.Value))}
+				return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
 			case *object.Array:
 				return &object.Integer{Value: int64(len(arg.Elements))}
+			case *object.Tuple:
+				return &object.Integer{Value: int64(len(arg.Elements))}
+			case *object.Range:
+				return &object.Integer{Value: arg.Len()}
+			case *object.Hash:
+				return &object.Integer{Value: int64(arg.Len())}
+			case *object.Set:
+				return &object.Integer{Value: int64(arg.Len())}
+			case *object.Enum:
+				return &object.Integer{Value: int64(len(arg.Variants))}
 			default:
-				return newError("argument to `lambai` not supported, got %s",
+				return newKindError(object.TYPE_ERROR, "argument to `lambai` not supported, got %s",
 					args[0].Type())
 			}
 		},
 	},
 	"print": {
 		Fn: func(stdout *[]string, args ...object.Object) object.Object {
-			for _, arg := range args {
-				if arg.Type() != object.STRING_OBJ && arg.Type() != object.INTEGER_OBJ && arg.Type() != object.BOOLEAN_OBJ && arg.Type() != object.NULL_OBJ && arg.Type() != object.ARRAY_OBJ {
-					return newError("argument `%s` of type %s not supported in `print`", arg, arg.Type())
-				}
-			}
 			s := ""
 
 			for _, arg := range args {
@@ -41,65 +56,269 @@ var builtins = map[string]*object.Builtin{
 			return &object.Null{}
 		},
 	},
-	"pehla": {
+	"galti": {
+		Fn: func(stdout *[]string, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or 2",
+					len(args))
+			}
+
+			for _, arg := range args {
+				if arg.Type() != object.STRING_OBJ {
+					return newKindError(object.TYPE_ERROR, "argument to `galti` must be STRING, got %s",
+						arg.Type())
+				}
+			}
+
+			exception := &object.Exception{Message: args[0].(*object.String).Value, Kind: object.USER_ERROR}
+			if len(args) == 2 {
+				exception.Kind = args[1].(*object.String).Value
+			}
+			exception.Value = args[0]
+
+			return exception
+		},
+	},
+	"tulna": {
+		Fn: func(stdout *[]string, args ...object.Object) object.Object {
+			if len(args) != 2 && len(args) != 3 {
+				return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2 or 3",
+					len(args))
+			}
+
+			collation := object.BYTE_COLLATION
+			if len(args) == 3 {
+				name, ok := args[2].(*object.String)
+				if !ok {
+					return newKindError(object.TYPE_ERROR, "collation passed to `tulna` must be STRING, got %s",
+						args[2].Type())
+				}
+
+				collation, ok = object.LookupCollation(name.Value)
+				if !ok {
+					return newKindError(object.VALUE_ERROR, "unknown collation: %s", name.Value)
+				}
+			}
+
+			cmp, ok := object.CompareCollated(args[0], args[1], collation)
+			if !ok {
+				return newKindError(object.TYPE_ERROR, "cannot order %s and %s",
+					args[0].Inspect(), args[1].Inspect())
+			}
+
+			switch {
+			case cmp < 0:
+				return &object.Integer{Value: -1}
+			case cmp > 0:
+				return &object.Integer{Value: 1}
+			default:
+				return &object.Integer{Value: 0}
+			}
+		},
+	},
+	"dashamlav": {
 		Fn: func(stdout *[]string, args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("wrong number of arguments. got=%d, want=1",
+				return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1",
 					len(args))
 			}
 
-			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument to `pehla` must be ARRAY, got %s",
+			if str, ok := args[0].(*object.String); ok {
+				// accepts the grouping Inspect writes, as in 1,23,456.50
+				decimal, ok := object.ParseDecimal(strings.ReplaceAll(strings.TrimSpace(str.Value), ",", ""))
+				if !ok {
+					return newKindError(object.VALUE_ERROR, "invalid decimal: %q", str.Value)
+				}
+				return decimal
+			}
+
+			decimal, ok := object.ToDecimal(args[0])
+			if !ok {
+				return newKindError(object.TYPE_ERROR, "argument to `dashamlav` must be STRING or a number, got %s",
 					args[0].Type())
 			}
 
-			arr := args[0].(*object.Array)
-			if len(arr.Elements) > 0 {
-				return arr.Elements[0]
+			return decimal
+		},
+	},
+	"golai": {
+		Fn: func(stdout *[]string, args ...object.Object) object.Object {
+			if len(args) != 2 && len(args) != 3 {
+				return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2 or 3",
+					len(args))
 			}
 
-			return NULL
+			decimal, ok := object.ToDecimal(args[0])
+			if !ok {
+				return newKindError(object.TYPE_ERROR, "argument to `golai` must be a number, got %s",
+					args[0].Type())
+			}
+
+			places, ok := args[1].(*object.Integer)
+			if !ok {
+				return newKindError(object.TYPE_ERROR, "places passed to `golai` must be INTEGER, got %s",
+					args[1].Type())
+			}
+			if places.Value < 0 || places.Value > maxPlaces {
+				return newKindError(object.VALUE_ERROR, "places passed to `golai` out of range: %d", places.Value)
+			}
+
+			mode := object.ROUND_HALF_UP
+			if len(args) == 3 {
+				name, ok := args[2].(*object.String)
+				if !ok {
+					return newKindError(object.TYPE_ERROR, "rounding mode passed to `golai` must be STRING, got %s",
+						args[2].Type())
+				}
+
+				mode, ok = object.LookupRoundingMode(name.Value)
+				if !ok {
+					return newKindError(object.VALUE_ERROR, "unknown rounding mode: %s", name.Value)
+				}
+			}
+
+			return decimal.Round(int32(places.Value), mode)
 		},
 	},
-	"aakhri": {
+	"divmod": {
+		Fn: func(stdout *[]string, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2",
+					len(args))
+			}
+			for _, arg := range args {
+				if arg.Type() != object.INTEGER_OBJ && arg.Type() != object.BIGINT_OBJ {
+					return newKindError(object.TYPE_ERROR, "arguments to `divmod` must be INTEGER, got %s",
+						arg.Type())
+				}
+			}
+
+			dividend, divisor := toBigInt(args[0]), toBigInt(args[1])
+			if divisor.Sign() == 0 {
+				return zeroDivisionError("//")
+			}
+
+			// the same pair as // and %: the quotient rounds down, so the
+			// remainder takes the divisor's sign
+			quotient, remainder := new(big.Int).QuoRem(dividend, divisor, new(big.Int))
+			if remainder.Sign() != 0 && remainder.Sign() != divisor.Sign() {
+				quotient.Sub(quotient, big.NewInt(1))
+				remainder.Add(remainder, divisor)
+			}
+
+			return &object.Tuple{Elements: []object.Object{object.NewInteger(quotient), object.NewInteger(remainder)}}
+		},
+	},
+	"kood": {
 		Fn: func(stdout *[]string, args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("wrong number of arguments. got=%d, want=1",
+				return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1",
 					len(args))
 			}
 
-			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument to `aakhri` must be ARRAY, got %s",
+			char, ok := args[0].(*object.Char)
+			if !ok {
+				return newKindError(object.TYPE_ERROR, "argument to `kood` must be CHAR, got %s",
 					args[0].Type())
 			}
 
-			arr := args[0].(*object.Array)
-			if len(arr.Elements) > 0 {
-				return arr.Elements[len(arr.Elements)-1]
+			return &object.Integer{Value: int64(char.Value)}
+		},
+	},
+	"akshar": {
+		Fn: func(stdout *[]string, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1",
+					len(args))
+			}
+
+			code, ok := args[0].(*object.Integer)
+			if !ok {
+				return newKindError(object.TYPE_ERROR, "argument to `akshar` must be INTEGER, got %s",
+					args[0].Type())
+			}
+			if code.Value < 0 || code.Value > unicode.MaxRune || !utf8.ValidRune(rune(code.Value)) {
+				return newKindError(object.VALUE_ERROR, "not a valid code point: %d", code.Value)
+			}
+
+			return &object.Char{Value: rune(code.Value)}
+		},
+	},
+	"svar_hai":    {Fn: classifyChar("svar_hai", svarTable)},
+	"vyanjan_hai": {Fn: classifyChar("vyanjan_hai", vyanjanTable)},
+	"matra_hai":   {Fn: classifyChar("matra_hai", matraTable)},
+	"halant_hai":  {Fn: classifyChar("halant_hai", halantTable)},
+	"pehla": {
+		Fn: func(stdout *[]string, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1",
+					len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *object.Array:
+				if len(arg.Elements) > 0 {
+					return arg.Elements[0]
+				}
+			case *object.Range:
+				if arg.Len() > 0 {
+					return &object.Integer{Value: arg.Start}
+				}
+			default:
+				return newKindError(object.TYPE_ERROR, "argument to `pehla` must be ARRAY, got %s",
+					args[0].Type())
 			}
 
 			return NULL
 		},
 	},
-	"baaki": {
+	"aakhri": {
 		Fn: func(stdout *[]string, args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("wrong number of arguments. got=%d, want=1",
+				return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1",
 					len(args))
 			}
 
-			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument to `aakhri` must be ARRAY, got %s",
+			switch arg := args[0].(type) {
+			case *object.Array:
+				if len(arg.Elements) > 0 {
+					return arg.Elements[len(arg.Elements)-1]
+				}
+			case *object.Range:
+				if arg.Len() > 0 {
+					return &object.Integer{Value: arg.At(arg.Len() - 1)}
+				}
+			default:
+				return newKindError(object.TYPE_ERROR, "argument to `aakhri` must be ARRAY, got %s",
 					args[0].Type())
 			}
 
-			arr := args[0].(*object.Array)
-			length := len(arr.Elements)
-			if length > 0 {
-				newElements := make([]object.Object, length-1, length-1)
-				copy(newElements, arr.Elements[1:length])
+			return NULL
+		},
+	},
+	"baaki": {
+		Fn: func(stdout *[]string, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1",
+					len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *object.Array:
+				length := len(arg.Elements)
+				if length > 0 {
+					newElements := make([]object.Object, length-1, length-1)
+					copy(newElements, arg.Elements[1:length])
 
-				return &object.Array{Elements: newElements}
+					return &object.Array{Elements: newElements}
+				}
+			case *object.Range:
+				if arg.Len() > 0 {
+					return &object.Range{Start: arg.Start + arg.Step, Step: arg.Step, Length: arg.Length - 1}
+				}
+			default:
+				return newKindError(object.TYPE_ERROR, "argument to `aakhri` must be ARRAY, got %s",
+					args[0].Type())
 			}
 
 			return NULL
@@ -108,11 +327,11 @@ var builtins = map[string]*object.Builtin{
 	"push": {
 		Fn: func(stdout *[]string, args ...object.Object) object.Object {
 			if len(args) != 2 {
-				return newError("wrong number of arguments. got=%d, want=2",
+				return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=2",
 					len(args))
 			}
 			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument to `push` must be ARRAY, got %s",
+				return newKindError(object.TYPE_ERROR, "argument to `push` must be ARRAY, got %s",
 					args[0].Type())
 			}
 
@@ -129,11 +348,11 @@ var builtins = map[string]*object.Builtin{
 	"pop": {
 		Fn: func(stdout *[]string, args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("wrong number of arguments. got=%d, want=1",
+				return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1",
 					len(args))
 			}
 			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("argument to `pop` must be ARRAY, got %s",
+				return newKindError(object.TYPE_ERROR, "argument to `pop` must be ARRAY, got %s",
 					args[0].Type())
 			}
 
@@ -146,4 +365,252 @@ var builtins = map[string]*object.Builtin{
 			return &object.Array{Elements: newElements}
 		},
 	},
+	"agla": {
+		Fn: func(stdout *[]string, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1 or 2",
+					len(args))
+			}
+
+			gen, ok := args[0].(*object.Generator)
+			if !ok {
+				return newKindError(object.TYPE_ERROR, "argument to `agla` must be GENERATOR, got %s",
+					args[0].Type())
+			}
+
+			next, ok := gen.Next(stdout)
+			if !ok {
+				if len(args) == 2 {
+					return args[1]
+				}
+				return newKindError(object.VALUE_ERROR, "%s is exhausted", gen.Inspect())
+			}
+
+			return next
+		},
+	},
+}
+
+// samuchchay iterates its argument, which for records and instances means
+// calling their __iter__, and so reaches back into builtins through Eval
+func init() {
+	builtins["samuchchay"] = &object.Builtin{
+		Fn: func(stdout *[]string, args ...object.Object) object.Object {
+			if len(args) > 1 {
+				return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=0 or 1",
+					len(args))
+			}
+
+			set := object.NewSet()
+			if len(args) == 0 {
+				return set
+			}
+
+			err := iterate(args[0], func(el object.Object) object.Object {
+				if !set.Add(el) {
+					return newKindError(object.TYPE_ERROR, "unusable as set element: %s", el.Type())
+				}
+				return nil
+			}, stdout)
+			if err != nil {
+				return err
+			}
+
+			return set
+		},
+	}
+}
+
+// methods reached with dot syntax, such as "abc".lambai() or arr.push(4), by
+// the type of their receiver; each is called with the receiver as its first
+// argument, so the free builtins can double as methods
+var methods = map[object.ObjectType]map[string]*object.Builtin{
+	object.STRING_OBJ: {
+		"lambai": asMethod(builtins["lambai"], 0),
+		"bada":   {Fn: mapString(strings.ToUpper)},
+		"chhota": {Fn: mapString(strings.ToLower)},
+		"todo": {
+			Fn: func(stdout *[]string, args ...object.Object) object.Object {
+				if len(args) != 2 {
+					return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1",
+						len(args)-1)
+				}
+
+				sep, ok := args[1].(*object.String)
+				if !ok {
+					return newKindError(object.TYPE_ERROR, "argument to `todo` must be STRING, got %s",
+						args[1].Type())
+				}
+
+				parts := strings.Split(args[0].(*object.String).Value, sep.Value)
+				elements := make([]object.Object, len(parts))
+				for i, part := range parts {
+					elements[i] = &object.String{Value: part}
+				}
+
+				return &object.Array{Elements: elements}
+			},
+		},
+	},
+	object.CHAR_OBJ: {
+		"kood":        asMethod(builtins["kood"], 0),
+		"svar_hai":    asMethod(builtins["svar_hai"], 0),
+		"vyanjan_hai": asMethod(builtins["vyanjan_hai"], 0),
+		"matra_hai":   asMethod(builtins["matra_hai"], 0),
+		"halant_hai":  asMethod(builtins["halant_hai"], 0),
+	},
+	object.ARRAY_OBJ: {
+		"lambai": asMethod(builtins["lambai"], 0),
+		"pehla":  asMethod(builtins["pehla"], 0),
+		"aakhri": asMethod(builtins["aakhri"], 0),
+		"baaki":  asMethod(builtins["baaki"], 0),
+		"push":   asMethod(builtins["push"], 1),
+		"pop":    asMethod(builtins["pop"], 0),
+		"jodo": {
+			Fn: func(stdout *[]string, args ...object.Object) object.Object {
+				if len(args) != 2 {
+					return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1",
+						len(args)-1)
+				}
+
+				sep, ok := args[1].(*object.String)
+				if !ok {
+					return newKindError(object.TYPE_ERROR, "argument to `jodo` must be STRING, got %s",
+						args[1].Type())
+				}
+
+				parts := []string{}
+				for _, el := range args[0].(*object.Array).Elements {
+					parts = append(parts, el.Inspect())
+				}
+
+				return &object.String{Value: strings.Join(parts, sep.Value)}
+			},
+		},
+	},
+	object.RANGE_OBJ: {
+		"lambai": asMethod(builtins["lambai"], 0),
+		"pehla":  asMethod(builtins["pehla"], 0),
+		"aakhri": asMethod(builtins["aakhri"], 0),
+		"baaki":  asMethod(builtins["baaki"], 0),
+	},
+	object.SET_OBJ: {
+		"lambai": asMethod(builtins["lambai"], 0),
+	},
+	object.TUPLE_OBJ: {
+		"lambai": asMethod(builtins["lambai"], 0),
+	},
+	object.HASH_OBJ: {
+		"lambai": asMethod(builtins["lambai"], 0),
+		"keys": {
+			Fn: func(stdout *[]string, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=0",
+						len(args)-1)
+				}
+
+				keys := []object.Object{}
+				for _, pair := range args[0].(*object.Hash).OrderedPairs() {
+					keys = append(keys, pair.Key)
+				}
+
+				return &object.Array{Elements: keys}
+			},
+		},
+		"values": {
+			Fn: func(stdout *[]string, args ...object.Object) object.Object {
+				if len(args) != 1 {
+					return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=0",
+						len(args)-1)
+				}
+
+				values := []object.Object{}
+				for _, pair := range args[0].(*object.Hash).OrderedPairs() {
+					values = append(values, pair.Value)
+				}
+
+				return &object.Array{Elements: values}
+			},
+		},
+	},
+	object.DECIMAL_OBJ: {
+		"golai": asMethod(builtins["golai"], 1, 2),
+	},
+}
+
+// reuses a free builtin as a method taking any of arities arguments besides
+// its receiver, so a wrong call is reported in the arguments the caller wrote
+func asMethod(builtin *object.Builtin, arities ...int) *object.Builtin {
+	want := make([]string, len(arities))
+	for i, arity := range arities {
+		want[i] = strconv.Itoa(arity)
+	}
+
+	return &object.Builtin{
+		Fn: func(stdout *[]string, args ...object.Object) object.Object {
+			for _, arity := range arities {
+				if len(args)-1 == arity {
+					return builtin.Fn(stdout, args...)
+				}
+			}
+
+			return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=%s",
+				len(args)-1, strings.Join(want, " or "))
+		},
+	}
+}
+
+// the Devanagari block sorted for the svar_hai family: independent vowels,
+// consonants (nukta forms included), dependent vowel signs and the halant
+var (
+	svarTable = &unicode.RangeTable{R16: []unicode.Range16{
+		{Lo: 0x0904, Hi: 0x0914, Stride: 1},
+		{Lo: 0x0960, Hi: 0x0961, Stride: 1},
+		{Lo: 0x0972, Hi: 0x0977, Stride: 1},
+	}}
+	vyanjanTable = &unicode.RangeTable{R16: []unicode.Range16{
+		{Lo: 0x0915, Hi: 0x0939, Stride: 1},
+		{Lo: 0x0958, Hi: 0x095f, Stride: 1},
+		{Lo: 0x0978, Hi: 0x097f, Stride: 1},
+	}}
+	matraTable = &unicode.RangeTable{R16: []unicode.Range16{
+		{Lo: 0x093a, Hi: 0x093b, Stride: 1},
+		{Lo: 0x093e, Hi: 0x094c, Stride: 1},
+		{Lo: 0x094e, Hi: 0x094f, Stride: 1},
+		{Lo: 0x0955, Hi: 0x0957, Stride: 1},
+		{Lo: 0x0962, Hi: 0x0963, Stride: 1},
+	}}
+	halantTable = &unicode.RangeTable{R16: []unicode.Range16{
+		{Lo: 0x094d, Hi: 0x094d, Stride: 1},
+	}}
+)
+
+// builds a builtin reporting whether its one CHAR argument is in table
+func classifyChar(name string, table *unicode.RangeTable) object.BuiltinFunction {
+	return func(stdout *[]string, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=1",
+				len(args))
+		}
+
+		char, ok := args[0].(*object.Char)
+		if !ok {
+			return newKindError(object.TYPE_ERROR, "argument to `%s` must be CHAR, got %s",
+				name, args[0].Type())
+		}
+
+		return nativeBoolToBooleanObject(unicode.Is(table, char.Value))
+	}
+}
+
+// builds a method that takes no arguments and maps its receiver string through fn
+func mapString(fn func(string) string) object.BuiltinFunction {
+	return func(stdout *[]string, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments. got=%d, want=0",
+				len(args)-1)
+		}
+
+		return &object.String{Value: fn(args[0].(*object.String).Value)}
+	}
 }
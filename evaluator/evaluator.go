@@ -2,9 +2,13 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
+	"math/big"
+	"strings"
 
 	"github.com/Suryansh-23/amrit/ast"
 	"github.com/Suryansh-23/amrit/object"
+	"github.com/Suryansh-23/amrit/token"
 )
 
 var (
@@ -14,6 +18,19 @@ var (
 )
 
 func Eval(node ast.Node, env *object.Environment, stdout *[]string) object.Object {
+	result := evalNode(node, env, stdout)
+
+	// errors take the position of the innermost node they surface from
+	if err, ok := result.(*object.Error); ok && err.Line == 0 {
+		if tok, ok := nodeToken(node); ok && tok.Line > 0 {
+			err.Line, err.Column = tok.Line, tok.Column
+		}
+	}
+
+	return result
+}
+
+func evalNode(node ast.Node, env *object.Environment, stdout *[]string) object.Object {
 	switch node := node.(type) {
 	// Statements
 	case *ast.Program:
@@ -26,6 +43,21 @@ func Eval(node ast.Node, env *object.Environment, stdout *[]string) object.Objec
 		return evalIfExpression(node, env, stdout)
 	case *ast.WhileExpression:
 		return evalWhileExpression(node, env, stdout)
+	case *ast.ForEachExpression:
+		return evalForEachExpression(node, env, stdout)
+	case *ast.MatchExpression:
+		return evalMatchExpression(node, env, stdout)
+	case *ast.TryExpression:
+		return evalTryExpression(node, env, stdout)
+	case *ast.ThrowStatement:
+		val := Eval(node.Value, env, stdout)
+		if isError(val) {
+			return val
+		}
+
+		return newThrownError(val)
+	case *ast.YieldStatement:
+		return evalYieldStatement(node, env, stdout)
 	case *ast.ReturnStatement:
 		val := Eval(node.ReturnValue, env, stdout)
 		if isError(val) {
@@ -34,10 +66,36 @@ func Eval(node ast.Node, env *object.Environment, stdout *[]string) object.Objec
 
 		return &object.ReturnValue{Value: val}
 	case *ast.FunctionLiteral:
-		params := node.Parameters
-		body := node.Body
+		if node.Name == nil {
+			return newFunction(node, env)
+		}
 
-		return &object.Function{Parameters: params, Body: body, Env: env}
+		// a named function expression can always refer to itself
+		fnEnv := object.NewEnclosedEnvironment(env)
+		return fnEnv.Set(node.Name.Value, newFunction(node, fnEnv))
+	case *ast.FunctionStatement:
+		// already bound by hoistFunctions when its block started
+		return nil
+	case *ast.StructStatement:
+		fields := []string{}
+		for _, f := range node.Fields {
+			fields = append(fields, f.Value)
+		}
+		methods := map[string]*object.Function{}
+		for _, m := range node.Methods {
+			methods[m.Name.Value] = newFunction(m, env)
+		}
+		env.Set(node.Name.Value, &object.RecordType{Name: node.Name.Value, Fields: fields, Methods: methods})
+	case *ast.ClassStatement:
+		return evalClassStatement(node, env, stdout)
+	case *ast.EnumStatement:
+		variants := []string{}
+		for _, v := range node.Variants {
+			variants = append(variants, v.Value)
+		}
+		env.Set(node.Name.Value, object.NewEnum(node.Name.Value, variants))
+	case *ast.MemberAssignStatement:
+		return evalMemberAssignStatement(node, env, stdout)
 	case *ast.CallExpression:
 		function := Eval(node.Function, env, stdout)
 		if isError(function) {
@@ -56,7 +114,19 @@ func Eval(node ast.Node, env *object.Environment, stdout *[]string) object.Objec
 			return val
 		}
 
-		env.Set(node.Name.Value, val)
+		if node.Pattern != nil {
+			if err := bindPattern(node.Pattern, val, env, stdout); err != nil {
+				return err
+			}
+		} else {
+			// `mana naam = karya(...) {...}` names an anonymous function after its binding
+			if fn, ok := val.(*object.Function); ok && fn.Name == "" {
+				if _, ok := node.Value.(*ast.FunctionLiteral); ok {
+					fn.Name = node.Name.Value
+				}
+			}
+			env.Set(node.Name.Value, val)
+		}
 	case *ast.CompoundAssignmentStatement:
 		val := Eval(node.Value, env, stdout)
 		if isError(val) {
@@ -65,18 +135,33 @@ func Eval(node ast.Node, env *object.Environment, stdout *[]string) object.Objec
 
 		initVal, ok := env.Get(node.Name.Value)
 		if !ok {
-			return newError("identifier not found: " + node.Name.Value)
+			return newKindError(object.NAME_ERROR, "identifier not found: %s", node.Name.Value)
 		}
 
-		env.Set(node.Name.Value, computeOp(node.Operator, initVal, val))
+		result := computeOp(node.Operator, initVal, val, stdout)
+		if isError(result) {
+			return result
+		}
+
+		env.Set(node.Name.Value, result)
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 
 		// Expressions
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
+	case *ast.BigIntegerLiteral:
+		return &object.BigInt{Value: node.Value}
+	case *ast.DecimalLiteral:
+		decimal, ok := object.ParseDecimal(node.Value)
+		if !ok {
+			return newKindError(object.VALUE_ERROR, "invalid decimal: %s", node.Value)
+		}
+		return decimal
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
+	case *ast.CharLiteral:
+		return &object.Char{Value: node.Value}
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
 	case *ast.ArrayLiteral:
@@ -86,6 +171,13 @@ func Eval(node ast.Node, env *object.Environment, stdout *[]string) object.Objec
 		}
 
 		return &object.Array{Elements: elements}
+	case *ast.TupleLiteral:
+		elements := evalExpressions(node.Elements, env, stdout)
+		if len(elements) == 1 && isError(elements[0]) {
+			return elements[0]
+		}
+
+		return &object.Tuple{Elements: elements}
 	case *ast.SliceExpression:
 		left := Eval(node.Left, env, stdout)
 		if isError(left) {
@@ -110,26 +202,37 @@ func Eval(node ast.Node, env *object.Environment, stdout *[]string) object.Objec
 		}
 
 		return evalIndexExpression(left, index)
+	case *ast.MemberExpression:
+		obj := Eval(node.Object, env, stdout)
+		if isError(obj) {
+			return obj
+		}
+
+		return evalMemberExpression(obj, node.Property.Value)
 	case *ast.SliceArrayExpression:
 		left := Eval(node.Left, env, stdout)
 		if isError(left) {
 			return left
 		}
 
-		slice := Eval(&node.Slice, env, stdout)
-		if isError(slice) {
-			return slice
+		bounds, err := evalSliceBounds(&node.Slice, env, stdout)
+		if err != nil {
+			return err
 		}
 
-		return evalSliceExpression(left, slice)
+		return evalSliceExpression(left, bounds)
 	case *ast.HashLiteral:
 		return evalHashLiteral(node, env, stdout)
+	case *ast.SetLiteral:
+		return evalSetLiteral(node, env, stdout)
+	case *ast.Comprehension:
+		return evalComprehension(node, env, stdout)
 	case *ast.PrefixExpression:
 		right := Eval(node.Right, env, stdout)
 		if isError(right) {
 			return right
 		}
-		return evalPrefixExpression(node.Operator, right)
+		return evalPrefixExpression(node.Operator, right, stdout)
 
 	case *ast.InfixExpression:
 		left := Eval(node.Left, env, stdout)
@@ -141,7 +244,9 @@ func Eval(node ast.Node, env *object.Environment, stdout *[]string) object.Objec
 		if isError(right) {
 			return right
 		}
-		return evalInfixExpression(node.Operator, left, right)
+		return evalInfixExpression(node.Operator, left, right, stdout)
+	case *ast.SpreadExpression:
+		return newError("spread operator not allowed here: %s", node.String())
 	case *ast.Comment:
 		return nil
 	}
@@ -170,14 +275,33 @@ func nativeBoolToBooleanObject(input bool) *object.Boolean {
 	return FALSE
 }
 
-func evalPrefixExpression(operator string, right object.Object) object.Object {
+func evalPrefixExpression(operator string, right object.Object, stdout *[]string) object.Object {
+	if operator == "-" {
+		if method, ok := findMethod(right, negateMethod); ok {
+			return applyFunction(method, []object.Object{}, stdout)
+		}
+	}
+
 	switch operator {
 	case "!":
 		return evalBangOperatorExpression(right)
 	case "-":
 		return evalMinusPrefixOperatorExpression(right)
+	case "~":
+		return evalTildePrefixOperatorExpression(right)
+	default:
+		return newKindError(object.TYPE_ERROR, "unknown operator: %s %s", operator, right.Type())
+	}
+}
+
+func evalTildePrefixOperatorExpression(right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: ^right.Value}
+	case *object.BigInt:
+		return object.NewInteger(new(big.Int).Not(right.Value))
 	default:
-		return newError("unknown operator: %s %s", operator, right.Type())
+		return newKindError(object.TYPE_ERROR, "unknown operator: ~%s", right.Type())
 	}
 }
 
@@ -195,56 +319,175 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 }
 
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
-	if right.Type() != object.INTEGER_OBJ {
-		return newError("unknown operator: -%s", right.Type())
+	switch right := right.(type) {
+	case *object.Integer:
+		if right.Value == math.MinInt64 {
+			return object.NewInteger(new(big.Int).Neg(big.NewInt(right.Value)))
+		}
+		return &object.Integer{Value: -right.Value}
+	case *object.BigInt:
+		return object.NewInteger(new(big.Int).Neg(right.Value))
+	case *object.Decimal:
+		return right.Neg()
+	default:
+		return newKindError(object.TYPE_ERROR, "unknown operator: -%s", right.Type())
 	}
-
-	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
 }
 
-func evalInfixExpression(operator string, left object.Object, right object.Object) object.Object {
+func evalInfixExpression(operator string, left object.Object, right object.Object, stdout *[]string) object.Object {
+	if result, ok := evalOperatorMethod(operator, left, right, stdout); ok {
+		return result
+	}
+
 	switch {
+	case operator == "mein":
+		return evalMembershipExpression(left, right, stdout)
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, left, right)
+	case isInteger(left) && isInteger(right):
+		return evalBigIntInfixExpression(operator, left, right)
+	case isNumber(left) && isNumber(right):
+		return evalDecimalInfixExpression(operator, left, right)
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right)
+	case operator == "+" && isText(left) && isText(right):
+		// joining characters, or characters and strings, builds a string
+		return &object.String{Value: left.Inspect() + right.Inspect()}
+	case left.Type() == object.SET_OBJ && right.Type() == object.SET_OBJ && operator != "==" && operator != "!=":
+		return evalSetInfixExpression(operator, left.(*object.Set), right.(*object.Set))
 	case operator == "==":
-		left := left.(*object.Boolean).Value
-		right := right.(*object.Boolean).Value
-		// fmt.Println(left, right, left == right, object.Boolean{Value: false} == object.Boolean{Value: false})
-		return nativeBoolToBooleanObject(left == right)
-
+		return nativeBoolToBooleanObject(object.Equals(left, right))
 	case operator == "!=":
-		left := left.(*object.Boolean).Value
-		right := right.(*object.Boolean).Value
-		return nativeBoolToBooleanObject(left != right)
-
+		return nativeBoolToBooleanObject(!object.Equals(left, right))
+	case isOrderingOperator(operator) && left.Type() == right.Type():
+		return evalOrderingExpression(operator, left, right)
 	case left.Type() != right.Type():
-		return newError("type mismatch: %s %s %s",
+		return newKindError(object.TYPE_ERROR, "type mismatch: %s %s %s",
 			left.Type(), operator, right.Type())
 
 	default:
-		return newError("unknown operator: %s %s %s",
+		return newKindError(object.TYPE_ERROR, "unknown operator: %s %s %s",
 			left.Type(), operator, right.Type())
 	}
 }
 
+// x mein container: element of an array, substring of a string, key of a hash
+// or integer within a range
+func evalMembershipExpression(element, container object.Object, stdout *[]string) object.Object {
+	switch container := container.(type) {
+	case *object.Array:
+		return evalContains(container.Elements, element, stdout)
+	case *object.Tuple:
+		return evalContains(container.Elements, element, stdout)
+	case *object.String:
+		if !isText(element) {
+			return newKindError(object.TYPE_ERROR, "`mein` on a STRING needs a STRING or CHAR, got %s", element.Type())
+		}
+		return nativeBoolToBooleanObject(strings.Contains(container.Value, element.Inspect()))
+	case *object.Hash:
+		if !isHashable(element) {
+			return newKindError(object.TYPE_ERROR, "unusable as hash key: %s", element.Type())
+		}
+		_, ok := container.Get(element)
+		return nativeBoolToBooleanObject(ok)
+	case *object.Set:
+		if !isHashable(element) {
+			return newKindError(object.TYPE_ERROR, "unusable as set element: %s", element.Type())
+		}
+		return nativeBoolToBooleanObject(container.Contains(element))
+	case *object.Range:
+		integer, ok := element.(*object.Integer)
+		if !ok {
+			return FALSE
+		}
+		return nativeBoolToBooleanObject(container.Contains(integer.Value))
+	default:
+		return newKindError(object.TYPE_ERROR, "`mein` not supported for %s", container.Type())
+	}
+}
+
+// whether element == one of elements, asked as == would ask it
+func evalContains(elements []object.Object, element object.Object, stdout *[]string) object.Object {
+	for _, el := range elements {
+		equal, err := evalEquals(element, el, stdout)
+		if err != nil {
+			return err
+		}
+		if equal {
+			return TRUE
+		}
+	}
+
+	return FALSE
+}
+
+// compares as == does, so through __barabar__ where left defines one; err is
+// the error that method ran into
+func evalEquals(left, right object.Object, stdout *[]string) (bool, object.Object) {
+	result := evalInfixExpression("==", left, right, stdout)
+	if isError(result) {
+		return false, result
+	}
+
+	return isTruthy(result), nil
+}
+
 func evalIntegerInfixExpression(operator string, left object.Object, right object.Object) object.Object {
 	leftVal := left.(*object.Integer).Value
 	rightVal := right.(*object.Integer).Value
 
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
+		if sum := leftVal + rightVal; (leftVal^sum)&(rightVal^sum) >= 0 {
+			return &object.Integer{Value: sum}
+		}
+		return evalBigIntInfixExpression(operator, left, right)
 	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
+		if diff := leftVal - rightVal; (leftVal^rightVal)&(leftVal^diff) >= 0 {
+			return &object.Integer{Value: diff}
+		}
+		return evalBigIntInfixExpression(operator, left, right)
 	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
-	case "/":
-		return &object.Integer{Value: leftVal / rightVal}
-	case "%":
-		return &object.Integer{Value: leftVal % rightVal}
+		if product := leftVal * rightVal; leftVal == 0 || product/leftVal == rightVal && !(leftVal == -1 && rightVal == math.MinInt64) {
+			return &object.Integer{Value: product}
+		}
+		return evalBigIntInfixExpression(operator, left, right)
+	case "/", "//", "%":
+		if rightVal == 0 {
+			return zeroDivisionError(operator)
+		}
+		if leftVal == math.MinInt64 && rightVal == -1 {
+			return evalBigIntInfixExpression(operator, left, right)
+		}
+
+		switch operator {
+		case "/":
+			return &object.Integer{Value: leftVal / rightVal}
+		case "//":
+			return &object.Integer{Value: floorDiv(leftVal, rightVal)}
+		default:
+			// the remainder of //, so it takes the divisor's sign
+			return &object.Integer{Value: leftVal - floorDiv(leftVal, rightVal)*rightVal}
+		}
+	case "**":
+		return evalBigIntInfixExpression(operator, left, right)
+	case "&":
+		return &object.Integer{Value: leftVal & rightVal}
+	case "^":
+		return &object.Integer{Value: leftVal ^ rightVal}
+	case "<<", ">>":
+		if rightVal < 0 {
+			return newKindError(object.VALUE_ERROR, "negative shift count: %d %s %d", leftVal, operator, rightVal)
+		}
+		if operator == ">>" {
+			return &object.Integer{Value: leftVal >> uint64(rightVal)}
+		}
+		if rightVal < 63 && (leftVal<<uint64(rightVal))>>uint64(rightVal) == leftVal {
+			return &object.Integer{Value: leftVal << uint64(rightVal)}
+		}
+		return evalBigIntInfixExpression(operator, left, right)
+	case "..", "..<":
+		return newRange(operator, leftVal, rightVal)
 	case "<":
 		return &object.Boolean{Value: leftVal < rightVal}
 	case "<=":
@@ -258,11 +501,189 @@ func evalIntegerInfixExpression(operator string, left object.Object, right objec
 	case "!=":
 		return &object.Boolean{Value: leftVal != rightVal}
 	default:
-		return newError("unknown operator: %s %s %s",
+		return newKindError(object.TYPE_ERROR, "unknown operator: %s %s %s",
+			left.Type(), operator, right.Type())
+	}
+}
+
+// divides and rounds towards negative infinity, unlike Go's truncating /
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+
+	return q
+}
+
+// builds start..stop or start..<stop, which can hold at most as many integers
+// as an INTEGER can count
+func newRange(operator string, start, stop int64) object.Object {
+	r, ok := object.NewRange(start, stop, 1)
+	if ok && operator == ".." && start <= stop {
+		ok = r.Length < math.MaxInt64
+		r.Length++
+	}
+	if !ok {
+		return newKindError(object.VALUE_ERROR, "range too long: %d %s %d", start, operator, stop)
+	}
+
+	return r
+}
+
+// does integer arithmetic at arbitrary precision, for operands that are
+// already BigInts or whose result would overflow an Integer
+func evalBigIntInfixExpression(operator string, left object.Object, right object.Object) object.Object {
+	leftVal := toBigInt(left)
+	rightVal := toBigInt(right)
+	result := new(big.Int)
+
+	switch operator {
+	case "+":
+		return object.NewInteger(result.Add(leftVal, rightVal))
+	case "-":
+		return object.NewInteger(result.Sub(leftVal, rightVal))
+	case "*":
+		return object.NewInteger(result.Mul(leftVal, rightVal))
+	case "/", "//", "%":
+		if rightVal.Sign() == 0 {
+			return zeroDivisionError(operator)
+		}
+
+		remainder := new(big.Int)
+		result.QuoRem(leftVal, rightVal, remainder)
+		if operator == "/" {
+			return object.NewInteger(result)
+		}
+
+		// the quotient rounds down, so the remainder takes the divisor's sign
+		if remainder.Sign() != 0 && leftVal.Sign() != rightVal.Sign() {
+			result.Sub(result, big.NewInt(1))
+			remainder.Add(remainder, rightVal)
+		}
+		if operator == "//" {
+			return object.NewInteger(result)
+		}
+		return object.NewInteger(remainder)
+	case "**":
+		if rightVal.Sign() < 0 {
+			return newKindError(object.VALUE_ERROR, "negative exponent: %s ** %s", left.Inspect(), right.Inspect())
+		}
+		// 0, 1 and -1 stay small whatever the power; anything else grows by
+		// at least a bit per power
+		if leftVal.CmpAbs(big.NewInt(1)) > 0 &&
+			(!rightVal.IsInt64() || rightVal.Int64() > maxShift/int64(leftVal.BitLen()-1)) {
+			return newKindError(object.VALUE_ERROR, "exponent too large: %s ** %s", left.Inspect(), right.Inspect())
+		}
+		return object.NewInteger(result.Exp(leftVal, rightVal, nil))
+	case "&":
+		return object.NewInteger(result.And(leftVal, rightVal))
+	case "^":
+		return object.NewInteger(result.Xor(leftVal, rightVal))
+	case "<<", ">>":
+		if rightVal.Sign() < 0 {
+			return newKindError(object.VALUE_ERROR, "negative shift count: %s %s %s", left.Inspect(), operator, right.Inspect())
+		}
+		if !rightVal.IsInt64() || rightVal.Int64() > maxShift {
+			return newKindError(object.VALUE_ERROR, "shift count too large: %s", right.Inspect())
+		}
+		if operator == "<<" {
+			return object.NewInteger(result.Lsh(leftVal, uint(rightVal.Int64())))
+		}
+		return object.NewInteger(result.Rsh(leftVal, uint(rightVal.Int64())))
+	case "<", "<=", ">", ">=", "==", "!=":
+		cmp := leftVal.Cmp(rightVal)
+		switch operator {
+		case "<":
+			return nativeBoolToBooleanObject(cmp < 0)
+		case "<=":
+			return nativeBoolToBooleanObject(cmp <= 0)
+		case ">":
+			return nativeBoolToBooleanObject(cmp > 0)
+		case ">=":
+			return nativeBoolToBooleanObject(cmp >= 0)
+		case "==":
+			return nativeBoolToBooleanObject(cmp == 0)
+		default:
+			return nativeBoolToBooleanObject(cmp != 0)
+		}
+	default:
+		return newKindError(object.TYPE_ERROR, "unknown operator: %s %s %s",
+			left.Type(), operator, right.Type())
+	}
+}
+
+// the largest shift a BigInt may take, which keeps 1 << n to a few megabytes;
+// it also caps the bits an exponentiation may produce
+const maxShift = 1 << 24
+
+func toBigInt(obj object.Object) *big.Int {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return big.NewInt(obj.Value)
+	case *object.BigInt:
+		return obj.Value
+	default:
+		return nil
+	}
+}
+
+func isInteger(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.BIGINT_OBJ
+}
+
+func isText(obj object.Object) bool {
+	return obj.Type() == object.STRING_OBJ || obj.Type() == object.CHAR_OBJ
+}
+
+func isNumber(obj object.Object) bool {
+	return isInteger(obj) || obj.Type() == object.DECIMAL_OBJ
+}
+
+// does exact decimal arithmetic once either operand is a Decimal; only a
+// quotient that never terminates is rounded, far past the operands' places
+func evalDecimalInfixExpression(operator string, left object.Object, right object.Object) object.Object {
+	leftVal, _ := object.ToDecimal(left)
+	rightVal, _ := object.ToDecimal(right)
+
+	switch operator {
+	case "+":
+		return leftVal.Add(rightVal)
+	case "-":
+		return leftVal.Sub(rightVal)
+	case "*":
+		return leftVal.Mul(rightVal)
+	case "/":
+		if rightVal.Value.Sign() == 0 {
+			return zeroDivisionError(operator)
+		}
+		return leftVal.Quo(rightVal, object.ROUND_HALF_EVEN)
+	case "<":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) < 0)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) <= 0)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) > 0)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) >= 0)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) != 0)
+	default:
+		return newKindError(object.TYPE_ERROR, "unknown operator: %s %s %s",
 			left.Type(), operator, right.Type())
 	}
 }
 
+func zeroDivisionError(operator string) *object.Error {
+	if operator == "%" {
+		return newKindError(object.ZERO_DIV_ERROR, "modulo by zero")
+	}
+
+	return newKindError(object.ZERO_DIV_ERROR, "division by zero")
+}
+
 func evalStringInfixExpression(operator string, left object.Object, right object.Object) object.Object {
 	leftVal := left.(*object.String).Value
 	rightVal := right.(*object.String).Value
@@ -274,10 +695,67 @@ func evalStringInfixExpression(operator string, left object.Object, right object
 		return &object.Boolean{Value: leftVal == rightVal}
 	case "!=":
 		return &object.Boolean{Value: leftVal != rightVal}
+	case "<", "<=", ">", ">=":
+		return evalOrderingExpression(operator, left, right)
+	default:
+		return newKindError(object.TYPE_ERROR, "unknown operator: %s %s %s",
+			left.Type(), operator, right.Type())
+	}
+}
+
+// + is union, & intersection, - difference and ^ symmetric difference
+func evalSetInfixExpression(operator string, left, right *object.Set) object.Object {
+	switch operator {
+	case "+":
+		return left.Union(right)
+	case "&":
+		return left.Intersection(right)
+	case "-":
+		return left.Difference(right)
+	case "^":
+		return left.SymmetricDifference(right)
+	default:
+		return newKindError(object.TYPE_ERROR, "unknown operator: %s %s %s",
+			left.Type(), operator, right.Type())
+	}
+}
+
+func isOrderingOperator(operator string) bool {
+	switch operator {
+	case "<", "<=", ">", ">=":
+		return true
 	default:
-		return newError("unknown operator: %s %s %s",
+		return false
+	}
+}
+
+// applies < <= > >= to any two objects with an ordering
+func evalOrderingExpression(operator string, left, right object.Object) object.Object {
+	if _, ok := left.(object.Comparer); !ok {
+		return newKindError(object.TYPE_ERROR, "unknown operator: %s %s %s",
 			left.Type(), operator, right.Type())
 	}
+
+	cmp, ok := object.Compare(left, right)
+	if !ok {
+		return newKindError(object.TYPE_ERROR, "cannot order %s and %s", left.Inspect(), right.Inspect())
+	}
+
+	return orderingResult(operator, cmp)
+}
+
+// turns the result of a three-way comparison into that of < <= > or >=
+func orderingResult(operator string, cmp int) *object.Boolean {
+	switch operator {
+	case "<":
+		return nativeBoolToBooleanObject(cmp < 0)
+	case "<=":
+		return nativeBoolToBooleanObject(cmp <= 0)
+	case ">":
+		return nativeBoolToBooleanObject(cmp > 0)
+	default:
+		return nativeBoolToBooleanObject(cmp >= 0)
+	}
 }
 
 func evalIfExpression(ie *ast.IfExpression, env *object.Environment, stdout *[]string) object.Object {
@@ -303,7 +781,14 @@ func evalWhileExpression(we *ast.WhileExpression, env *object.Environment, stdou
 	}
 
 	for isTruthy(condition) {
-		Eval(we.Body, env, stdout)
+		result := Eval(we.Body, env, stdout)
+		if result != nil {
+			rt := result.Type()
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+				return result
+			}
+		}
+
 		condition = Eval(we.Condition, env, stdout)
 
 		if isError(condition) {
@@ -314,114 +799,612 @@ func evalWhileExpression(we *ast.WhileExpression, env *object.Environment, stdou
 	return NULL
 }
 
-func isTruthy(obj object.Object) bool {
-	switch obj := obj.(type) {
-	case *object.Null:
-		return false
-	case *object.Boolean:
-		return obj.Value
-	default:
-		return true
+func evalForEachExpression(fe *ast.ForEachExpression, env *object.Environment, stdout *[]string) object.Object {
+	iterable := Eval(fe.Iterable, env, stdout)
+	if isError(iterable) {
+		return iterable
 	}
-}
 
-func evalBlockStatement(block *ast.BlockStatement, env *object.Environment, stdout *[]string) object.Object {
-	var result object.Object
-	for _, statement := range block.Statements {
-		result = Eval(statement, env, stdout)
+	result := iterate(iterable, func(element object.Object) object.Object {
+		if err := bindPattern(fe.Variable, element, env, stdout); err != nil {
+			return err
+		}
 
+		result := Eval(fe.Body, env, stdout)
 		if result != nil {
 			rt := result.Type()
 			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
 				return result
 			}
 		}
+
+		return nil
+	}, stdout)
+	if result != nil {
+		return result
 	}
 
-	return result
+	return NULL
 }
 
-func evalProgram(program *ast.Program, env *object.Environment, stdout *[]string) object.Object {
-	var result object.Object
-	for _, statement := range program.Statements {
-		result = Eval(statement, env, stdout)
-
-		switch result := result.(type) {
-		case *object.ReturnValue:
-			return result.Value
-		case *object.Error:
+// calls fn with each element of an iterable in turn, stopping early with
+// whatever non-nil object fn returns. Records and instances are iterable
+// through an __iter__ method returning something that is
+func iterate(iterable object.Object, fn func(object.Object) object.Object, stdout *[]string) object.Object {
+	if method, ok := findMethod(iterable, iterMethod); ok {
+		result := applyFunction(method, []object.Object{}, stdout)
+		if isError(result) {
 			return result
 		}
+		if result == nil {
+			result = NULL
+		}
+		if _, ok := result.(object.Iterable); !ok {
+			return newKindError(object.TYPE_ERROR, "%s must return an iterable, got %s", iterMethod, result.Type())
+		}
+		iterable = result
 	}
-	return result
-}
 
-func newError(format string, a ...interface{}) *object.Error {
-	return &object.Error{Message: fmt.Sprintf(format, a...)}
-}
+	it, ok := iterable.(object.Iterable)
+	if !ok {
+		return newKindError(object.TYPE_ERROR, "cannot iterate over %s", iterable.Type())
+	}
 
-func isError(obj object.Object) bool {
-	if obj != nil {
-		return obj.Type() == object.ERROR_OBJ
+	elements := it.Iter()
+	// a generator is finished off however the loop ends, rather than left
+	// suspended for good
+	if gen, ok := elements.(*object.Generator); ok {
+		defer gen.Close(stdout)
+	}
+
+	for {
+		element, ok := elements.Next(stdout)
+		if !ok {
+			return nil
+		}
+		if isError(element) {
+			return element
+		}
+		if result := fn(element); result != nil {
+			return result
+		}
 	}
-	return false
 }
 
-func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
-	if val, ok := env.Get(node.Value); ok {
-		return val
+// runs the body of the first arm whose pattern equals the subject, or of the
+// _ arm if none does
+func evalMatchExpression(me *ast.MatchExpression, env *object.Environment, stdout *[]string) object.Object {
+	subject := Eval(me.Subject, env, stdout)
+	if isError(subject) {
+		return subject
 	}
 
-	if builtin, ok := builtins[node.Value]; ok {
-		return builtin
+	for _, arm := range me.Arms {
+		if arm.Pattern != nil {
+			pattern := Eval(arm.Pattern, env, stdout)
+			if isError(pattern) {
+				return pattern
+			}
+			equal, err := evalEquals(subject, pattern, stdout)
+			if err != nil {
+				return err
+			}
+			if !equal {
+				continue
+			}
+		}
+
+		return Eval(arm.Body, env, stdout)
 	}
 
-	return newError("identifier not found: " + node.Value)
+	return newKindError(object.VALUE_ERROR, "no milao arm matches %s", subject.Inspect())
 }
 
-func evalExpressions(exps []ast.Expression, env *object.Environment, stdout *[]string) []object.Object {
-	var result []object.Object
+// runs the koshish block, hands an Error escaping it to the pakdo block, and
+// always runs the aakhir block; a labh or error inside aakhir wins over the
+// others. Closing a generator isn't an error pakdo can stop
+func evalTryExpression(te *ast.TryExpression, env *object.Environment, stdout *[]string) object.Object {
+	result := Eval(te.Block, env, stdout)
 
-	for _, e := range exps {
-		evaluated := Eval(e, env, stdout)
-		if isError(evaluated) {
-			return []object.Object{evaluated}
+	if err, ok := result.(*object.Error); ok && te.Catch != nil && err.Kind != object.GENERATOR_EXIT {
+		if te.CatchParam != nil {
+			env.Set(te.CatchParam.Value, newException(err))
 		}
-		result = append(result, evaluated)
+		result = Eval(te.Catch, env, stdout)
+	}
+
+	if te.Finally != nil {
+		finally := Eval(te.Finally, env, stdout)
+		if finally != nil {
+			ft := finally.Type()
+			if ft == object.RETURN_VALUE_OBJ || ft == object.ERROR_OBJ {
+				return finally
+			}
+		}
+	}
+
+	if result == nil {
+		return NULL
 	}
 
 	return result
 }
 
-func applyFunction(fn object.Object, args []object.Object, stdout *[]string) object.Object {
-	switch fn := fn.(type) {
-	case *object.Function:
-		extendEnv := extendFunctionEnv(fn, args)
-		evaluated := Eval(fn.Body, extendEnv, stdout)
-		return unwrapReturnValue(evaluated)
+// turns the operand of phenko into an Error; rethrowing a caught Exception keeps its kind and position
+func newThrownError(val object.Object) *object.Error {
+	switch val := val.(type) {
+	case *object.Exception:
+		return &object.Error{Message: val.Message, Kind: val.Kind, Line: val.Line, Column: val.Column, Value: val.Value}
+	case *object.String:
+		return &object.Error{Message: val.Value, Kind: object.USER_ERROR, Value: val}
+	default:
+		return &object.Error{Message: val.Inspect(), Kind: object.USER_ERROR, Value: val}
+	}
+}
+
+func newException(err *object.Error) *object.Exception {
+	value := err.Value
+	if value == nil {
+		value = &object.String{Value: err.Message}
+	}
+
+	return &object.Exception{Message: err.Message, Kind: err.Kind, Line: err.Line, Column: err.Column, Value: value}
+}
+
+func isTruthy(obj object.Object) bool {
+	switch obj := obj.(type) {
+	case *object.Null:
+		return false
+	case *object.Boolean:
+		return obj.Value
+	default:
+		return true
+	}
+}
+
+// reports whether statement is a comment standing alone, which does nothing
+// and so mustn't become the value of the block or program it ends
+func isComment(statement ast.Statement) bool {
+	es, ok := statement.(*ast.ExpressionStatement)
+	if !ok {
+		return false
+	}
+	_, ok = es.Expression.(*ast.Comment)
+	return ok
+}
+
+func evalBlockStatement(block *ast.BlockStatement, env *object.Environment, stdout *[]string) object.Object {
+	var result object.Object
+
+	hoistFunctions(block.Statements, env)
+	for _, statement := range block.Statements {
+		if isComment(statement) {
+			continue
+		}
+		result = Eval(statement, env, stdout)
+
+		if result != nil {
+			rt := result.Type()
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+				return result
+			}
+		}
+	}
+
+	return result
+}
+
+func evalProgram(program *ast.Program, env *object.Environment, stdout *[]string) object.Object {
+	var result object.Object
+
+	hoistFunctions(program.Statements, env)
+	for _, statement := range program.Statements {
+		if isComment(statement) {
+			continue
+		}
+		result = Eval(statement, env, stdout)
+
+		switch result := result.(type) {
+		case *object.ReturnValue:
+			return result.Value
+		case *object.Error:
+			return result
+		}
+	}
+	return result
+}
+
+// binds every function declared in stmts before any of them run, so that
+// declarations can be called before they appear and can be mutually recursive
+func hoistFunctions(stmts []ast.Statement, env *object.Environment) {
+	for _, stmt := range stmts {
+		if decl, ok := stmt.(*ast.FunctionStatement); ok {
+			env.Set(decl.Name.Value, newFunction(decl.Function, env))
+		}
+	}
+}
+
+func newFunction(node *ast.FunctionLiteral, env *object.Environment) *object.Function {
+	fn := &object.Function{Parameters: node.Parameters, Body: node.Body, Env: env, Generator: node.Generator}
+	if node.Name != nil {
+		fn.Name = node.Name.Value
+	}
+
+	return fn
+}
+
+func newError(format string, a ...interface{}) *object.Error {
+	return newKindError(object.RUNTIME_ERROR, format, a...)
+}
+
+func newKindError(kind string, format string, a ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...), Kind: kind}
+}
+
+// returns the token that best locates node in the source, for error positions
+func nodeToken(node ast.Node) (token.Token, bool) {
+	switch node := node.(type) {
+	case *ast.LetStatement:
+		if node.Name != nil {
+			return node.Name.Token, true
+		}
+		return node.Token, true
+	case *ast.CompoundAssignmentStatement:
+		return node.Name.Token, true
+	case *ast.ExpressionStatement:
+		return node.Token, true
+	case *ast.ThrowStatement:
+		return node.Token, true
+	case *ast.YieldStatement:
+		return node.Token, true
+	case *ast.Identifier:
+		return node.Token, true
+	case *ast.PrefixExpression:
+		return node.Token, true
+	case *ast.InfixExpression:
+		return node.Token, true
+	case *ast.CallExpression:
+		return node.Token, true
+	case *ast.IndexExpression:
+		return node.Token, true
+	case *ast.MemberExpression:
+		return node.Property.Token, true
+	case *ast.MemberAssignStatement:
+		return node.Target.Property.Token, true
+	case *ast.ClassStatement:
+		return node.Token, true
+	case *ast.MatchExpression:
+		return node.Token, true
+	case *ast.SliceArrayExpression:
+		return node.Token, true
+	case *ast.HashLiteral:
+		return node.Token, true
+	case *ast.SetLiteral:
+		return node.Token, true
+	case *ast.Comprehension:
+		return node.Token, true
+	case *ast.TupleLiteral:
+		return node.Token, true
+	case *ast.ArrayLiteral:
+		return node.Token, true
+	default:
+		return token.Token{}, false
+	}
+}
+
+func isError(obj object.Object) bool {
+	if obj != nil {
+		return obj.Type() == object.ERROR_OBJ
+	}
+	return false
+}
+
+func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
+	if val, ok := env.Get(node.Value); ok {
+		return val
+	}
+
+	if builtin, ok := builtins[node.Value]; ok {
+		return builtin
+	}
+
+	return newKindError(object.NAME_ERROR, "identifier not found: %s", node.Value)
+}
+
+func evalExpressions(exps []ast.Expression, env *object.Environment, stdout *[]string) []object.Object {
+	var result []object.Object
+
+	for _, e := range exps {
+		if spread, ok := e.(*ast.SpreadExpression); ok {
+			evaluated := Eval(spread.Value, env, stdout)
+			if isError(evaluated) {
+				return []object.Object{evaluated}
+			}
+
+			arr, ok := evaluated.(*object.Array)
+			if !ok {
+				return []object.Object{newKindError(object.TYPE_ERROR, "cannot spread %s, want ARRAY", evaluated.Type())}
+			}
+			result = append(result, arr.Elements...)
+			continue
+		}
+
+		evaluated := Eval(e, env, stdout)
+		if isError(evaluated) {
+			return []object.Object{evaluated}
+		}
+		result = append(result, evaluated)
+	}
+
+	return result
+}
+
+func applyFunction(fn object.Object, args []object.Object, stdout *[]string) object.Object {
+	switch fn := fn.(type) {
+	case *object.Function:
+		extendEnv, err := extendFunctionEnv(fn, args, stdout)
+		if err != nil {
+			return err
+		}
+		if fn.Generator {
+			return newGenerator(fn, extendEnv, stdout)
+		}
+		evaluated := unwrapReturnValue(Eval(fn.Body, extendEnv, stdout))
+		if evaluated == nil {
+			// a body ending in a statement with no value, such as mana
+			return NULL
+		}
+		return evaluated
 
 	case *object.Builtin:
 		return fn.Fn(stdout, args...)
 
+	case *object.RecordType:
+		return newRecord(fn, args)
+
+	case *object.Class:
+		return newInstance(fn, args, stdout)
+
+	case *object.BoundMethod:
+		// methods written in amrit already see their receiver as yeh
+		if _, ok := fn.Method.(*object.Builtin); ok {
+			args = append([]object.Object{fn.Receiver}, args...)
+		}
+		return applyFunction(fn.Method, args, stdout)
+
 	default:
-		return newError("not a function: %s", fn.Type())
+		return newKindError(object.TYPE_ERROR, "not a function: %s", fn.Type())
+	}
+
+}
+
+// returns a generator that runs fn's body in env, its arguments already
+// bound, a step at a time. What the body returns is dropped; only an error
+// it ends with reaches whoever is iterating
+func newGenerator(fn *object.Function, env *object.Environment, stdout *[]string) *object.Generator {
+	gen := object.NewGenerator(fn.Name, func(yield func(object.Object) bool, stdout *[]string) object.Object {
+		env.SetYield(yield)
+
+		result := unwrapReturnValue(Eval(fn.Body, env, stdout))
+		if isError(result) {
+			return result
+		}
+		return nil
+	})
+	env.Track(gen, stdout)
+
+	return gen
+}
+
+// hands a value to whoever resumed the generator and waits to be resumed
+func evalYieldStatement(ys *ast.YieldStatement, env *object.Environment, stdout *[]string) object.Object {
+	val := Eval(ys.Value, env, stdout)
+	if isError(val) {
+		return val
 	}
 
+	yield := env.Yield()
+	if yield == nil {
+		return newError("upaj outside a generator")
+	}
+	if !yield(val) {
+		return newKindError(object.GENERATOR_EXIT, "generator closed")
+	}
+
+	return nil
 }
 
-func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
+func extendFunctionEnv(fn *object.Function, args []object.Object, stdout *[]string) (*object.Environment, object.Object) {
+	if err := checkArity(fn, args); err != nil {
+		return nil, err
+	}
+
 	env := object.NewEnclosedEnvironment(fn.Env)
 
 	for paramIdx, param := range fn.Parameters {
-		env.Set(param.Value, args[paramIdx])
+		var err object.Object
+
+		switch param := param.(type) {
+		case *ast.SpreadExpression:
+			rest := make([]object.Object, len(args)-paramIdx)
+			copy(rest, args[paramIdx:])
+			err = bindPattern(param.Value, &object.Array{Elements: rest}, env, stdout)
+		case *ast.DefaultParameter:
+			if paramIdx < len(args) {
+				err = bindPattern(param.Name, args[paramIdx], env, stdout)
+				break
+			}
+
+			// defaults are evaluated in the call's environment so they can use earlier parameters
+			val := Eval(param.Default, env, stdout)
+			if isError(val) {
+				return nil, val
+			}
+			err = bindPattern(param.Name, val, env, stdout)
+		default:
+			err = bindPattern(param, args[paramIdx], env, stdout)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return env, nil
+}
+
+// checks the number of arguments against the required, optional and rest parameters of fn
+func checkArity(fn *object.Function, args []object.Object) object.Object {
+	required, optional, variadic := 0, 0, false
+
+	for _, param := range fn.Parameters {
+		switch param.(type) {
+		case *ast.SpreadExpression:
+			variadic = true
+		case *ast.DefaultParameter:
+			optional++
+		default:
+			required++
+		}
+	}
+
+	name := ""
+	if fn.Name != "" {
+		name = fmt.Sprintf(" to `%s`", fn.Name)
+	}
+
+	switch {
+	case variadic && len(args) < required:
+		return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments%s. got=%d, want at least %d",
+			name, len(args), required)
+	case variadic:
+		return nil
+	case optional == 0 && len(args) != required:
+		return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments%s. got=%d, want=%d",
+			name, len(args), required)
+	case len(args) < required || len(args) > required+optional:
+		return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments%s. got=%d, want between %d and %d",
+			name, len(args), required, required+optional)
+	}
+
+	return nil
+}
+
+// binds val to the names in pattern, returning an error if the shapes don't match
+func bindPattern(pattern ast.Expression, val object.Object, env *object.Environment, stdout *[]string) object.Object {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		env.Set(pattern.Value, val)
+		return nil
+	case *ast.ArrayLiteral:
+		return bindArrayPattern(pattern, val, env, stdout)
+	case *ast.TupleLiteral:
+		return bindTuplePattern(pattern, val, env, stdout)
+	case *ast.HashLiteral:
+		return bindHashPattern(pattern, val, env, stdout)
+	default:
+		return newError("invalid destructuring pattern: %s", pattern.String())
+	}
+}
+
+// unpacks a tuple, or an array, into the names of mana q, r = ...
+func bindTuplePattern(pattern *ast.TupleLiteral, val object.Object, env *object.Environment, stdout *[]string) object.Object {
+	var values []object.Object
+	switch val := val.(type) {
+	case *object.Tuple:
+		values = val.Elements
+	case *object.Array:
+		values = val.Elements
+	default:
+		return newKindError(object.TYPE_ERROR, "cannot destructure %s as TUPLE", val.Type())
+	}
+
+	if len(values) != len(pattern.Elements) {
+		return newKindError(object.VALUE_ERROR, "wrong number of values to destructure. got=%d, want=%d",
+			len(values), len(pattern.Elements))
+	}
+
+	for i, el := range pattern.Elements {
+		if err := bindPattern(el, values[i], env, stdout); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func bindArrayPattern(pattern *ast.ArrayLiteral, val object.Object, env *object.Environment, stdout *[]string) object.Object {
+	arr, ok := val.(*object.Array)
+	if !ok {
+		return newKindError(object.TYPE_ERROR, "cannot destructure %s as ARRAY", val.Type())
+	}
+
+	elements := pattern.Elements
+	var rest *ast.SpreadExpression
+	if len(elements) > 0 {
+		if spread, ok := elements[len(elements)-1].(*ast.SpreadExpression); ok {
+			rest = spread
+			elements = elements[:len(elements)-1]
+		}
+	}
+
+	if rest == nil && len(arr.Elements) != len(elements) {
+		return newKindError(object.VALUE_ERROR, "wrong number of values to destructure. got=%d, want=%d",
+			len(arr.Elements), len(elements))
+	}
+	if rest != nil && len(arr.Elements) < len(elements) {
+		return newKindError(object.VALUE_ERROR, "wrong number of values to destructure. got=%d, want at least %d",
+			len(arr.Elements), len(elements))
+	}
+
+	for i, el := range elements {
+		if err := bindPattern(el, arr.Elements[i], env, stdout); err != nil {
+			return err
+		}
+	}
+
+	if rest != nil {
+		remaining := make([]object.Object, len(arr.Elements)-len(elements))
+		copy(remaining, arr.Elements[len(elements):])
+
+		return bindPattern(rest.Value, &object.Array{Elements: remaining}, env, stdout)
+	}
+
+	return nil
+}
+
+func bindHashPattern(pattern *ast.HashLiteral, val object.Object, env *object.Environment, stdout *[]string) object.Object {
+	hash, ok := val.(*object.Hash)
+	if !ok {
+		return newKindError(object.TYPE_ERROR, "cannot destructure %s as HASH", val.Type())
+	}
+
+	for _, pair := range pattern.Pairs {
+		key := Eval(pair.Key, env, stdout)
+		if isError(key) {
+			return key
+		}
+
+		if !isHashable(key) {
+			return newKindError(object.TYPE_ERROR, "unusable as hash key: %s", key.Type())
+		}
+
+		entry, ok := hash.Get(key)
+		if !ok {
+			return newKindError(object.VALUE_ERROR, "key not found while destructuring: %s", key.Inspect())
+		}
+
+		if err := bindPattern(pair.Value, entry.Value, env, stdout); err != nil {
+			return err
+		}
 	}
 
-	return env
+	return nil
 }
 
 func unwrapReturnValue(obj object.Object) object.Object {
 	if returnValue, ok := obj.(*object.ReturnValue); ok {
-		return returnValue
+		return returnValue.Value
 	}
 
 	return obj
@@ -431,113 +1414,626 @@ func evalIndexExpression(left, index object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.TUPLE_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalArrayIndexExpression(&object.Array{Elements: left.(*object.Tuple).Elements}, index)
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalStringIndexExpression(left, index)
+	case left.Type() == object.RANGE_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalRangeIndexExpression(left, index)
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpression(left, index)
+	case left.Type() == object.EXCEPTION_OBJ && index.Type() == object.STRING_OBJ:
+		return evalExceptionIndexExpression(left, index)
 	default:
-		return newError("index operator not supported %s", left.Type())
+		return newKindError(object.TYPE_ERROR, "index operator not supported %s", left.Type())
+	}
+}
+
+// resolves a possibly negative index against length, reporting whether it's in bounds
+func normalizeIndex(idx, length int64) (int64, bool) {
+	if idx < 0 {
+		idx += length
 	}
+
+	return idx, 0 <= idx && idx < length
 }
 
 func evalArrayIndexExpression(arr, index object.Object) object.Object {
 	arrObj := arr.(*object.Array)
-	idx := index.(*object.Integer).Value
-	max := int64(len(arrObj.Elements) - 1)
-
-	if idx < 0 || max < idx {
+	idx, ok := normalizeIndex(index.(*object.Integer).Value, int64(len(arrObj.Elements)))
+	if !ok {
 		return NULL
 	}
 
 	return arrObj.Elements[idx]
 }
 
-func evalSliceExpression(left, slice object.Object) object.Object {
-	switch {
-	case left.Type() == object.ARRAY_OBJ && slice.Type() == object.SLICE_OBJ:
-		return evalArraySliceExpression(left, slice)
+func evalStringIndexExpression(str, index object.Object) object.Object {
+	runes := []rune(str.(*object.String).Value)
+	idx, ok := normalizeIndex(index.(*object.Integer).Value, int64(len(runes)))
+	if !ok {
+		return NULL
+	}
+
+	return &object.Char{Value: runes[idx]}
+}
+
+func evalRangeIndexExpression(rng, index object.Object) object.Object {
+	r := rng.(*object.Range)
+	idx, ok := normalizeIndex(index.(*object.Integer).Value, r.Len())
+	if !ok {
+		return NULL
+	}
+
+	return &object.Integer{Value: r.At(idx)}
+}
+
+// builds a record from one argument per field, in declaration order
+func newRecord(recordType *object.RecordType, args []object.Object) object.Object {
+	if len(args) != len(recordType.Fields) {
+		return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments to `%s`. got=%d, want=%d",
+			recordType.Name, len(args), len(recordType.Fields))
+	}
+
+	fields := make(map[string]object.Object, len(args))
+	for i, name := range recordType.Fields {
+		fields[name] = args[i]
+	}
+
+	return &object.Record{RecordType: recordType, Fields: fields}
+}
+
+func evalMemberExpression(obj object.Object, field string) object.Object {
+	switch obj := obj.(type) {
+	case *object.Record:
+		if val, ok := obj.Get(field); ok {
+			return val
+		}
+		if method, ok := obj.RecordType.Methods[field]; ok {
+			return bindMethod(method, nil, obj)
+		}
+		return newKindError(object.NAME_ERROR, "%s has no field: %s", obj.RecordType.Name, field)
+	case *object.Instance:
+		if val, ok := obj.Get(field); ok {
+			return val
+		}
+		if method, definedIn, ok := obj.Class.FindMethod(field); ok {
+			return bindMethod(method, definedIn, obj)
+		}
+		return newKindError(object.NAME_ERROR, "%s has no field or method: %s", obj.Class.Name, field)
+	case *object.Enum:
+		if variant, ok := obj.Variant(field); ok {
+			return variant
+		}
+		return newKindError(object.NAME_ERROR, "%s has no variant: %s", obj.Name, field)
+	case *object.Super:
+		if method, definedIn, ok := obj.Class.FindMethod(field); ok {
+			return bindMethod(method, definedIn, obj.Instance)
+		}
+		return newKindError(object.NAME_ERROR, "%s has no method: %s", obj.Class.Name, field)
+	case *object.Exception:
+		return evalExceptionIndexExpression(obj, &object.String{Value: field})
 	default:
-		return newError("slice operator not supported %s", left.Type())
+		if method, ok := methods[obj.Type()][field]; ok {
+			return &object.BoundMethod{Receiver: obj, Name: field, Method: method}
+		}
+		if _, ok := methods[obj.Type()]; ok {
+			return newKindError(object.NAME_ERROR, "%s has no method: %s", obj.Type(), field)
+		}
+		return newKindError(object.TYPE_ERROR, "field access not supported %s", obj.Type())
 	}
 }
 
-func evalArraySliceExpression(arr, slice object.Object) object.Object {
-	arrObj := arr.(*object.Array)
-	left := slice.(*object.Slice).Left.(*object.Integer).Value
-	right := slice.(*object.Slice).Right.(*object.Integer).Value
-	max := int64(len(arrObj.Elements))
+func evalMemberAssignStatement(node *ast.MemberAssignStatement, env *object.Environment, stdout *[]string) object.Object {
+	obj := Eval(node.Target.Object, env, stdout)
+	if isError(obj) {
+		return obj
+	}
 
-	if left < 0 || max < left || right < 0 || max < right || left > right {
-		return NULL
+	field := node.Target.Property.Value
+
+	// records only have their declared fields, while an instance gains a
+	// field the first time one is assigned
+	var current object.Object
+	switch obj := obj.(type) {
+	case *object.Record:
+		val, ok := obj.Get(field)
+		if !ok {
+			return newKindError(object.NAME_ERROR, "%s has no field: %s", obj.RecordType.Name, field)
+		}
+		if obj.Frozen {
+			return newKindError(object.TYPE_ERROR, "cannot assign %s.%s: it is a hash key or set element", obj.RecordType.Name, field)
+		}
+		current = val
+	case *object.Instance:
+		val, ok := obj.Get(field)
+		if !ok && node.Operator != "=" {
+			return newKindError(object.NAME_ERROR, "%s has no field: %s", obj.Class.Name, field)
+		}
+		current = val
+	default:
+		return newKindError(object.TYPE_ERROR, "field assignment not supported %s", obj.Type())
+	}
+
+	val := Eval(node.Value, env, stdout)
+	if isError(val) {
+		return val
+	}
+
+	if node.Operator != "=" {
+		val = computeOp(node.Operator, current, val, stdout)
+		if isError(val) {
+			return val
+		}
+	}
+
+	switch obj := obj.(type) {
+	case *object.Record:
+		obj.Set(field, val)
+	case *object.Instance:
+		obj.Set(field, val)
+	}
+	return nil
+}
+
+func evalClassStatement(node *ast.ClassStatement, env *object.Environment, stdout *[]string) object.Object {
+	class := &object.Class{Name: node.Name.Value, Methods: map[string]*object.Function{}}
+
+	if node.Parent != nil {
+		parent := Eval(node.Parent, env, stdout)
+		if isError(parent) {
+			return parent
+		}
+
+		parentClass, ok := parent.(*object.Class)
+		if !ok {
+			return newKindError(object.TYPE_ERROR, "varg %s cannot inherit from %s", class.Name, parent.Type())
+		}
+		class.Parent = parentClass
+	}
+
+	for _, method := range node.Methods {
+		class.Methods[method.Name.Value] = newFunction(method, env)
+	}
+
+	env.Set(class.Name, class)
+	return nil
+}
+
+// the names a class's constructor may go by
+var constructorNames = []string{"naya", "नया"}
+
+// builds an instance of class and runs its constructor, if it or a parent has one
+func newInstance(class *object.Class, args []object.Object, stdout *[]string) object.Object {
+	instance := object.NewInstance(class)
+
+	for _, name := range constructorNames {
+		if constructor, definedIn, ok := class.FindMethod(name); ok {
+			if result := applyFunction(bindMethod(constructor, definedIn, instance), args, stdout); isError(result) {
+				return result
+			}
+			return instance
+		}
+	}
+
+	if len(args) != 0 {
+		return newKindError(object.ARGUMENT_ERROR, "wrong number of arguments to `%s`. got=%d, want=0",
+			class.Name, len(args))
+	}
+
+	return instance
+}
+
+// binds a method to the record or instance it was looked up on: its body
+// sees the receiver as yeh, and for a method of class definedIn, mool reaches
+// the methods of definedIn's parent
+func bindMethod(method *object.Function, definedIn *object.Class, receiver object.Object) *object.BoundMethod {
+	env := object.NewEnclosedEnvironment(method.Env)
+	env.Set(token.SELF_LATIN, receiver)
+	if instance, ok := receiver.(*object.Instance); ok && definedIn.Parent != nil {
+		env.Set(token.SUPER_LATIN, &object.Super{Instance: instance, Class: definedIn.Parent})
+	}
+
+	bound := *method
+	bound.Env = env
+
+	return &object.BoundMethod{Receiver: receiver, Name: method.Name, Method: &bound}
+}
+
+func evalExceptionIndexExpression(exception, index object.Object) object.Object {
+	exc := exception.(*object.Exception)
+
+	switch field := index.(*object.String).Value; field {
+	case "sandesh":
+		return &object.String{Value: exc.Message}
+	case "prakar":
+		return &object.String{Value: exc.Kind}
+	case "pankti":
+		return &object.Integer{Value: int64(exc.Line)}
+	case "stambh":
+		return &object.Integer{Value: int64(exc.Column)}
+	case "mulya":
+		return exc.Value
+	default:
+		return newKindError(object.NAME_ERROR, "exception has no field: %s", field)
+	}
+}
+
+// evaluates the start, stop and step of a slice, leaving nil for those left open
+func evalSliceBounds(se *ast.SliceExpression, env *object.Environment, stdout *[]string) ([]*int64, object.Object) {
+	bounds := make([]*int64, 3)
+
+	for i, node := range []ast.Expression{se.Left, se.Right, se.Step} {
+		if node == nil {
+			continue
+		}
+
+		bound := Eval(node, env, stdout)
+		if isError(bound) {
+			return nil, bound
+		}
+
+		integer, ok := bound.(*object.Integer)
+		if !ok {
+			return nil, newKindError(object.TYPE_ERROR, "slice indices must be INTEGER, got %s", bound.Type())
+		}
+		bounds[i] = &integer.Value
+	}
+
+	return bounds, nil
+}
+
+// picks the indices a slice selects out of length elements the way Python does:
+// negative bounds count from the end and out of range bounds are clamped
+func sliceIndices(length int64, bounds []*int64) (*object.Range, object.Object) {
+	step := int64(1)
+	if bounds[2] != nil {
+		step = *bounds[2]
+	}
+	if step == 0 {
+		return nil, newKindError(object.VALUE_ERROR, "slice step cannot be zero")
+	}
+
+	lower, upper := int64(0), length
+	if step < 0 {
+		lower, upper = -1, length-1
+	}
+
+	clamp := func(bound *int64, fallback int64) int64 {
+		if bound == nil {
+			return fallback
+		}
+
+		idx := *bound
+		if idx < 0 {
+			idx += length
+		}
+		if idx < lower {
+			return lower
+		}
+		if idx > upper {
+			return upper
+		}
+
+		return idx
+	}
+
+	// never longer than length, so always built
+	if step > 0 {
+		indices, _ := object.NewRange(clamp(bounds[0], lower), clamp(bounds[1], upper), step)
+		return indices, nil
 	}
 
-	return &object.Array{Elements: arrObj.Elements[left:right]}
+	indices, _ := object.NewRange(clamp(bounds[0], upper), clamp(bounds[1], lower), step)
+	return indices, nil
+}
+
+func evalSliceExpression(left object.Object, bounds []*int64) object.Object {
+	switch left := left.(type) {
+	case *object.Tuple:
+		sliced := evalSliceExpression(&object.Array{Elements: left.Elements}, bounds)
+		if arr, ok := sliced.(*object.Array); ok {
+			return &object.Tuple{Elements: arr.Elements}
+		}
+		return sliced
+	case *object.Array:
+		indices, err := sliceIndices(int64(len(left.Elements)), bounds)
+		if err != nil {
+			return err
+		}
+
+		elements := make([]object.Object, indices.Len())
+		for i := range elements {
+			elements[i] = left.Elements[indices.At(int64(i))]
+		}
+
+		return &object.Array{Elements: elements}
+	case *object.String:
+		runes := []rune(left.Value)
+		indices, err := sliceIndices(int64(len(runes)), bounds)
+		if err != nil {
+			return err
+		}
+
+		sliced := make([]rune, indices.Len())
+		for i := range sliced {
+			sliced[i] = runes[indices.At(int64(i))]
+		}
+
+		return &object.String{Value: string(sliced)}
+	case *object.Range:
+		indices, err := sliceIndices(left.Len(), bounds)
+		if err != nil {
+			return err
+		}
+
+		sliced := &object.Range{Start: left.Start, Step: left.Step, Length: indices.Len()}
+		if indices.Len() > 0 {
+			sliced.Start = left.At(indices.Start)
+		}
+		// the step only matters once there are two integers, and then it
+		// fits in an int64 unless they lie too far apart
+		if indices.Len() > 1 {
+			sliced.Step = left.Step * indices.Step
+			if sliced.Step/indices.Step != left.Step || left.Step == math.MinInt64 && indices.Step == -1 {
+				return newKindError(object.VALUE_ERROR, "slice step out of range: %d", indices.Step)
+			}
+		}
+
+		return sliced
+	default:
+		return newKindError(object.TYPE_ERROR, "slice operator not supported %s", left.Type())
+	}
 }
 
-func computeOp(operator string, left, right object.Object) object.Object {
+func computeOp(operator string, left, right object.Object, stdout *[]string) object.Object {
 	switch {
-	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
-		return computeIntegerOp(operator, left, right)
+	case isNumber(left) && isNumber(right):
+		return computeNumberOp(operator, left, right, stdout)
 	default:
-		return newError("type mismatch: %s %s %s",
+		if result, ok := evalOperatorMethod(strings.TrimSuffix(operator, "="), left, right, stdout); ok {
+			return result
+		}
+		return newKindError(object.TYPE_ERROR, "type mismatch: %s %s %s",
 			left.Type(), operator, right.Type())
 	}
 }
 
-func computeIntegerOp(operator string, left, right object.Object) object.Object {
-	leftVal := left.(*object.Integer).Value
-	rightVal := right.(*object.Integer).Value
+// applies a compound assignment such as += or **= through the matching infix operator
+func computeNumberOp(operator string, left, right object.Object, stdout *[]string) object.Object {
+	if !strings.HasSuffix(operator, "=") {
+		return newKindError(object.TYPE_ERROR, "unknown operator: %s %s %s",
+			left.Type(), operator, right.Type())
+	}
 
-	switch operator {
-	case "+=":
-		return &object.Integer{Value: leftVal + rightVal}
-	case "-=":
-		return &object.Integer{Value: leftVal - rightVal}
-	case "*=":
-		return &object.Integer{Value: leftVal * rightVal}
-	case "/=":
-		return &object.Integer{Value: leftVal / rightVal}
+	return evalInfixExpression(strings.TrimSuffix(operator, "="), left, right, stdout)
+}
+
+// the methods a dhancha or varg defines to give its values an operator
+var operatorMethods = map[string]string{
+	"+":  "__jod__",
+	"-":  "__ghatao__",
+	"*":  "__guna__",
+	"/":  "__bhag__",
+	"%":  "__shesh__",
+	"==": "__barabar__",
+	"!=": "__barabar__",
+	"<":  "__tulna__",
+	"<=": "__tulna__",
+	">":  "__tulna__",
+	">=": "__tulna__",
+}
+
+// the method giving a value a prefix minus
+const negateMethod = "__ulta__"
+
+// the method making a record or instance iterable
+const iterMethod = "__iter__"
+
+// looks a method up on a record or instance, bound to it
+func findMethod(obj object.Object, name string) (*object.BoundMethod, bool) {
+	switch obj := obj.(type) {
+	case *object.Record:
+		if method, ok := obj.RecordType.Methods[name]; ok {
+			return bindMethod(method, nil, obj), true
+		}
+	case *object.Instance:
+		if method, definedIn, ok := obj.Class.FindMethod(name); ok {
+			return bindMethod(method, definedIn, obj), true
+		}
+	}
+
+	return nil, false
+}
+
+// applies an operator through the method its left operand defines for it,
+// reporting false when it defines none. __barabar__ answers both == and !=,
+// and __tulna__ returns a negative, zero or positive integer for all four
+// orderings
+func evalOperatorMethod(operator string, left, right object.Object, stdout *[]string) (object.Object, bool) {
+	name, ok := operatorMethods[operator]
+	if !ok {
+		return nil, false
+	}
+
+	method, ok := findMethod(left, name)
+	if !ok {
+		return nil, false
+	}
+
+	result := applyFunction(method, []object.Object{right}, stdout)
+	if isError(result) {
+		return result, true
+	}
+	if result == nil {
+		result = NULL
+	}
+
+	switch name {
+	case "__barabar__":
+		return nativeBoolToBooleanObject(isTruthy(result) == (operator == "==")), true
+	case "__tulna__":
+		if !isInteger(result) {
+			return newKindError(object.TYPE_ERROR, "%s must return INTEGER, got %s", name, result.Type()), true
+		}
+
+		return orderingResult(operator, toBigInt(result).Sign()), true
 	default:
-		return newError("unknown operator: %s %s %s",
-			left.Type(), operator, right.Type())
+		return result, true
 	}
 }
 
 func evalHashLiteral(node *ast.HashLiteral, env *object.Environment, stdout *[]string) object.Object {
-	pairs := make(map[object.HashKey]object.HashPair)
+	hash := object.NewHash()
 
-	for keyNode, valueNode := range node.Pairs {
-		key := Eval(keyNode, env, stdout)
+	for _, pair := range node.Pairs {
+		key := Eval(pair.Key, env, stdout)
 		if isError(key) {
 			return key
 		}
 
-		hashKey, ok := key.(object.Hashable)
-		if !ok {
-			return newError("unusable as hash key: %s", key.Type())
+		if !isHashable(key) {
+			return newKindError(object.TYPE_ERROR, "unusable as hash key: %s", key.Type())
 		}
 
-		value := Eval(valueNode, env, stdout)
+		value := Eval(pair.Value, env, stdout)
 		if isError(value) {
 			return value
 		}
 
-		hashed := hashKey.HashKey()
-		pairs[hashed] = object.HashPair{Key: key, Value: value}
+		hash.Set(key, value)
+	}
+	return hash
+}
+
+func evalSetLiteral(node *ast.SetLiteral, env *object.Environment, stdout *[]string) object.Object {
+	elements := evalExpressions(node.Elements, env, stdout)
+	if len(elements) == 1 && isError(elements[0]) {
+		return elements[0]
+	}
+
+	set := object.NewSet()
+	for _, el := range elements {
+		if !set.Add(el) {
+			return newKindError(object.TYPE_ERROR, "unusable as set element: %s", el.Type())
+		}
 	}
-	return &object.Hash{Pairs: pairs}
+
+	return set
+}
+
+// builds the array, set or hash of a comprehension. Its clauses run in an
+// environment of its own, so the loop variables don't outlive it
+func evalComprehension(node *ast.Comprehension, env *object.Environment, stdout *[]string) object.Object {
+	inner := object.NewEnclosedEnvironment(env)
+
+	var result object.Object
+	var collect func() object.Object
+	switch {
+	case node.Token.Literal == "[":
+		arr := &object.Array{Elements: []object.Object{}}
+		collect = func() object.Object {
+			el := Eval(node.Value, inner, stdout)
+			if isError(el) {
+				return el
+			}
+			arr.Elements = append(arr.Elements, el)
+			return nil
+		}
+		result = arr
+	case node.Key == nil:
+		set := object.NewSet()
+		collect = func() object.Object {
+			el := Eval(node.Value, inner, stdout)
+			if isError(el) {
+				return el
+			}
+			if !set.Add(el) {
+				return newKindError(object.TYPE_ERROR, "unusable as set element: %s", el.Type())
+			}
+			return nil
+		}
+		result = set
+	default:
+		hash := object.NewHash()
+		collect = func() object.Object {
+			key := Eval(node.Key, inner, stdout)
+			if isError(key) {
+				return key
+			}
+			if !isHashable(key) {
+				return newKindError(object.TYPE_ERROR, "unusable as hash key: %s", key.Type())
+			}
+
+			value := Eval(node.Value, inner, stdout)
+			if isError(value) {
+				return value
+			}
+			hash.Set(key, value)
+			return nil
+		}
+		result = hash
+	}
+
+	if err := evalClauses(node.Clauses, inner, stdout, collect); err != nil {
+		return err
+	}
+
+	return result
+}
+
+// runs the first of clauses, then the rest for each element a har clause
+// loops over, or only if an agar clause holds; collect is called each time
+// the last clause passes
+func evalClauses(clauses []*ast.ComprehensionClause, env *object.Environment, stdout *[]string, collect func() object.Object) object.Object {
+	if len(clauses) == 0 {
+		return collect()
+	}
+	clause := clauses[0]
+
+	if clause.Variable == nil {
+		condition := Eval(clause.Condition, env, stdout)
+		if isError(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return nil
+		}
+
+		return evalClauses(clauses[1:], env, stdout, collect)
+	}
+
+	iterable := Eval(clause.Iterable, env, stdout)
+	if isError(iterable) {
+		return iterable
+	}
+
+	return iterate(iterable, func(element object.Object) object.Object {
+		if err := bindPattern(clause.Variable, element, env, stdout); err != nil {
+			return err
+		}
+
+		return evalClauses(clauses[1:], env, stdout, collect)
+	}, stdout)
 }
 
 func evalHashIndexExpression(hash, index object.Object) object.Object {
 	hashObj := hash.(*object.Hash)
 
-	key, ok := index.(object.Hashable)
-	if !ok {
-		return newError("unusable as hash key: %s", index.Type())
+	if !isHashable(index) {
+		return newKindError(object.TYPE_ERROR, "unusable as hash key: %s", index.Type())
 	}
 
-	pair, ok := hashObj.Pairs[key.HashKey()]
+	pair, ok := hashObj.Get(index)
 	if !ok {
 		return NULL
 	}
 
 	return pair.Value
 }
+
+// reports whether obj can be a hash key or set element
+func isHashable(obj object.Object) bool {
+	_, ok := object.HashKeyOf(obj)
+	return ok
+}
@@ -30,6 +30,28 @@ func TestEvalIntegerExpression(t *testing.T) {
 		{"3 * 3 * 3 + 10", 37},
 		{"3 * (3 * 3) + 10", 37},
 		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"2 ** 10", 1024},
+		{"2 ** 3 ** 2", 512},
+		{"-2 ** 2", -4},
+		{"(-2) ** 3", -8},
+		{"5 ** 0", 1},
+		{"7 // 2", 3},
+		{"-7 // 2", -4},
+		{"7 // -2", -4},
+		{"-7 // -2", 3},
+		{"7 % 2", 1},
+		{"-7 % 2", 1},
+		{"7 % -2", -1},
+		{"-7 % -2", -1},
+		{"-6 % 3", 0},
+		{"-7 // 2 * 2 + -7 % 2", -7},
+		{"12 & 10", 8},
+		{"12 ^ 10", 6},
+		{"~5", -6},
+		{"1 << 10", 1024},
+		{"1024 >> 3", 128},
+		{"-16 >> 2", -4},
+		{"1 + 2 << 3", 24},
 	}
 
 	for _, tt := range tests {
@@ -86,6 +108,28 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"(1 < 2) == asatya", false},
 		{"(1 > 2) == satya", false},
 		{"(1 > 2) == asatya", true},
+		{"[1, [2, 3]] == [1, [2, 3]]", true},
+		{"[1, 2] == [1, 3]", false},
+		{"[1, 2] != [2, 1]", true},
+		{`{"a": [1]} == {"a": [1]}`, true},
+		{`{"a": 1} == {"a": 2}`, false},
+		{`1 == "1"`, false},
+		{`1 != "1"`, true},
+		{"[1] == 1", false},
+		{"agar (asatya) { 1 } == agar (asatya) { 2 }", true},
+		{"agar (asatya) { 1 } == 1", false},
+		{"mana f = karya() { 1 }| f == f", true},
+		{"karya() { 1 } == karya() { 1 }", false},
+		{"1..3 == 1..<4", true},
+		{`"aam" < "kela"`, true},
+		{`"kela" <= "kela"`, true},
+		{`"b" > "abc"`, true},
+		{`"अमर" < "आम"`, true},
+		{`"कमल" >= "कमल"`, true},
+		{"[1, 2] < [1, 3]", true},
+		{"[1, 2] < [1, 2, 0]", true},
+		{"[2] >= [1, 9]", true},
+		{`[["a"]] < [["b"]]`, true},
 	}
 
 	for _, tt := range tests {
@@ -160,6 +204,38 @@ func testNullObject(t *testing.T, obj object.Object) bool {
 	return true
 }
 
+// checks what input evaluated to against expected: an int or bool is the
+// value itself, a string what the object inspects as, an errorMessage the
+// message of the error it must be, and nil means NULL
+func testExpectedObject(t *testing.T, input string, obj object.Object, expected interface{}) bool {
+	switch expected := expected.(type) {
+	case int:
+		return testIntegerObject(t, obj, int64(expected))
+	case bool:
+		return testBooleanObject(t, obj, expected)
+	case string:
+		if obj.Inspect() != expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%q", input, expected, obj.Inspect())
+			return false
+		}
+	case errorMessage:
+		errObj, ok := obj.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, obj, obj)
+			return false
+		}
+		if errObj.Message != string(expected) {
+			t.Errorf("wrong error message for %q. expected=%q, got=%q", input, expected, errObj.Message)
+			return false
+		}
+	case nil:
+		return testNullObject(t, obj)
+	default:
+		t.Fatalf("unsupported expected value for %q: %T", input, expected)
+	}
+	return true
+}
+
 func TestReturnStatements(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -229,10 +305,106 @@ agar (10 > 1) {
 			"foobar",
 			"identifier not found: foobar",
 		},
+		{
+			"2 ** -1",
+			"negative exponent: 2 ** -1",
+		},
+		{
+			"1 << -1",
+			"negative shift count: 1 << -1",
+		},
+		{
+			"2 ** 100000000",
+			"exponent too large: 2 ** 100000000",
+		},
+		{
+			"(2 ** 64) ** 300000",
+			"exponent too large: 18446744073709551616 ** 300000",
+		},
+		{
+			"3 ** (2 ** 64)",
+			"exponent too large: 3 ** 18446744073709551616",
+		},
+		{
+			"16 ** 4611686018427387904",
+			"exponent too large: 16 ** 4611686018427387904",
+		},
+		{
+			"(-3) ** 9223372036854775807",
+			"exponent too large: -3 ** 9223372036854775807",
+		},
+		{
+			"~satya",
+			"unknown operator: ~BOOLEAN",
+		},
 		{
 			`"acha" - "kaise ho?"`,
 			"unknown operator: STRING - STRING",
 		},
+		{
+			"1 / 0",
+			"division by zero",
+		},
+		{
+			"5 // 0",
+			"division by zero",
+		},
+		{
+			"5 % 0",
+			"modulo by zero",
+		},
+		{
+			"(2 ** 64) / 0",
+			"division by zero",
+		},
+		{
+			"mana x = 1| x /= 0|",
+			"division by zero",
+		},
+		{
+			"1.5d / 0",
+			"division by zero",
+		},
+		{
+			"1.5d % 1",
+			"unknown operator: DECIMAL % INTEGER",
+		},
+		{
+			`dashamlav("barah")`,
+			`invalid decimal: "barah"`,
+		},
+		{
+			`golai(1.5d, 0, "jaldi")`,
+			"unknown rounding mode: jaldi",
+		},
+		{
+			`golai(1.5d, -1)`,
+			"places passed to `golai` out of range: -1",
+		},
+		{
+			`golai(1.5d, 2000000000)`,
+			"places passed to `golai` out of range: 2000000000",
+		},
+		{
+			`golai(1.5d, 4097)`,
+			"places passed to `golai` out of range: 4097",
+		},
+		{
+			"2 ** 64 + satya",
+			"type mismatch: BIGINT + BOOLEAN",
+		},
+		{
+			`[1] < ["a"]`,
+			"cannot order [1] and [a]",
+		},
+		{
+			`{} < {}`,
+			"unknown operator: HASH < HASH",
+		},
+		{
+			`satya < asatya`,
+			"unknown operator: BOOLEAN < BOOLEAN",
+		},
 		{
 			`{"name": "Monkey"}[karya(x) { x }]|`,
 			"unusable as hash key: FUNCTION",
@@ -262,7 +434,43 @@ func TestLetStatements(t *testing.T) {
 		{"mana a = 5 * 5 | a |", 25},
 		{"mana a = 5 | mana b = a | b |", 5},
 		{"mana a = 5 | mana b = a | mana c = a + b + 5 | c |", 15},
+		{"mana a = 5\n-1\na", 5},
+		{"mana a = [1, 2]\n[3]\na[0]", 1},
+		{"mana a = 1 +\n2\na", 3},
+		{"mana a = \"ab\" # ek\n.lambai()\na", 2},
+		{"mana a = \"ab\"\n/* ek */\n.lambai()\na", 2},
+		{"mana a = [1, 2] # ek\n|> lambai\na", 2},
+		{"mana a = 1 + # ek\n2\na", 3},
+		{"mana a = 1 + /* ek */ 2| a", 3},
+		{"mana a = agar (asatya) { 1 } # ek\nvarna { 2 }\na", 2},
+		{"mana f = karya() {\n1 # ek\n}\nf() # do", 1},
+		{"mana t = (1, 2)\n# ek\n(3, 4) |> lambai\nt[0]", 1},
+		{"mana a = [1]\n# ek\n[5][0]", 5},
+		{"mana a = [1]\n# ek\n[5][0]\na[0]", 1},
+		{"mana a = 1\n/* ek */\n-1", -1},
+		{"# ek\n-1", -1},
+		{"karya f() {\n# ek\n(2)\n}\nf()", 2},
+	}
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestCompoundAssignments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"mana a = 5| a += 3| a|", 8},
+		{"mana a = 17| a %= 5| a|", 2},
+		{"mana a = 3| a **= 4| a|", 81},
+		{"mana a = -7| a //= 2| a|", -4},
+		{"mana a = 12| a &= 10| a|", 8},
+		{"mana a = 12| a ^= 10| a|", 6},
+		{"mana a = 3| a <<= 2| a|", 12},
+		{"mana a = 64| a >>= 3| a|", 8},
 	}
+
 	for _, tt := range tests {
 		testIntegerObject(t, testEval(tt.input), tt.expected)
 	}
@@ -318,6 +526,345 @@ addTwo(2)|`
 	testIntegerObject(t, testEval(input), 4)
 }
 
+func TestBigIntegers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775807 - 2", "-9223372036854775809"},
+		{"4611686018427387904 * 2", "9223372036854775808"},
+		{"-1 * -9223372036854775808", "9223372036854775808"},
+		{"-(-9223372036854775808)", "9223372036854775808"},
+		{"-9223372036854775808 / -1", "9223372036854775808"},
+		{"2 ** 100", "1267650600228229401496703205376"},
+		{"1 ** (2 ** 64)", "1"},
+		{"(-1) ** 100000001", "-1"},
+		{"0 ** 100000000", "0"},
+		{"1 << 64", "18446744073709551616"},
+		{"(1 << 64) >> 60", "16"},
+		{"100000000000000000000 - 99999999999999999999", "1"},
+		{"(2 ** 64) // -3", "-6148914691236517206"},
+		{"(2 ** 64) % 7", "2"},
+		{"-(2 ** 64) % 7", "5"},
+		{"(2 ** 64) % -7", "-5"},
+		{"-9223372036854775808 % -1", "0"},
+		{"~(2 ** 64)", "-18446744073709551617"},
+		{"2 ** 64 > 9223372036854775807", "satya"},
+		{"2 ** 64 == 2 ** 64", "satya"},
+		{"2 ** 64 == 1 << 64", "satya"},
+		{"mana x = 9223372036854775807| x += 1| x", "9223372036854775808"},
+		{"mana f = karya(n) { agar (n < 2) { labh n| } labh f(n - 1) + f(n - 2)| }| f(20)", "6765"},
+		{`mana fact = karya(n) { agar (n < 2) { labh 1| } labh n * fact(n - 1)| }| fact(25)`, "15511210043330985984000000"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+
+	// results that fit back in 64 bits are plain integers again
+	testIntegerObject(t, testEval("(2 ** 64) / (2 ** 60)"), 16)
+	testIntegerObject(t, testEval("-9223372036854775808"), -9223372036854775808)
+}
+
+func TestDecimals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"12.50d", "12.50"},
+		{"0.1d + 0.2d", "0.3"},
+		{"0.1d + 0.2d == 0.3d", "satya"},
+		{"12.50d * 3", "37.50"},
+		{"100d - 0.01d", "99.99"},
+		{"1d / 3", "0.3333333333333333333333333333"},
+		{"10d / 4", "2.5"},
+		{"-12.50d", "-12.50"},
+		{"12.5d == 12.50d", "satya"},
+		{"12.0d == 12", "satya"},
+		{"12.01d > 12", "satya"},
+		{"[12.0d] == [12]", "satya"},
+		{"123456789.5d", "12,34,56,789.5"},
+		{`dashamlav("12.50")`, "12.50"},
+		{`dashamlav("1,23,456.75")`, "1,23,456.75"},
+		{"dashamlav(7)", "7"},
+		{"golai(2.345d, 2)", "2.35"},
+		{`golai(2.345d, 2, "half_even")`, "2.34"},
+		{`golai(2.341d, 2, "up")`, "2.35"},
+		{"golai(5, 2)", "5.00"},
+		{"golai(1.5d, 4096) == 1.5d", "satya"},
+		{"mana daam = 499.99d| mana gst = golai(daam * 18 / 100, 2)| daam + gst", "589.99"},
+		{"mana kul = 0d| har (d mein [10.10d, 20.20d, 30.30d]) { kul += d| }| kul", "60.60"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestRecords(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`dhancha Vyakti { naam, umar }| mana p = Vyakti("Asha", 12)| p.umar`, 12},
+		{`dhancha Vyakti { naam, umar }| Vyakti("Asha", 12)`, "Vyakti{naam: Asha, umar: 12}"},
+		{`dhancha Vyakti { naam, umar }| Vyakti`, "dhancha Vyakti { naam, umar }"},
+		{`dhancha Vyakti { naam, umar }| mana p = Vyakti("Asha", 12)| p.umar = 13| p.umar`, 13},
+		{`dhancha Vyakti { naam, umar }| mana p = Vyakti("Asha", 12)| p.umar += 5| p.umar`, 17},
+		{`dhancha Vyakti { naam, umar }| mana p = Vyakti("Asha", 12)| mana q = p| q.umar = 1| p.umar`, 1},
+		{`dhancha Bindu { x, y }| dhancha Rekha { a, b }| mana r = Rekha(Bindu(0, 0), Bindu(3, 4))| r.b.x * r.b.y`, 12},
+		{`dhancha Bindu { x, y }| mana r = [Bindu(1, 2)]| r[0].y`, 2},
+		{`dhancha Bindu { x, y }| Bindu(1, 2) == Bindu(1, 2)`, true},
+		{`dhancha Bindu { x, y }| Bindu(1, 2) == Bindu(2, 1)`, false},
+		{`dhancha A { x }| dhancha B { x }| A(1) == B(1)`, false},
+		{`ढाँचा Bindu { x, y }| Bindu(5, 6).x`, 5},
+		{`dhancha Vyakti { naam, umar }| Vyakti("Asha", 12).nam`, errorMessage("Vyakti has no field: nam")},
+		{`dhancha Vyakti { naam, umar }| mana p = Vyakti("Asha", 12)| p.umr = 3|`, errorMessage("Vyakti has no field: umr")},
+		{`dhancha Vyakti { naam, umar }| Vyakti("Asha")`, errorMessage("wrong number of arguments to `Vyakti`. got=1, want=2")},
+		{`mana x = 5| x.naam`, errorMessage("field access not supported INTEGER")},
+		{`mana x = {"a": 1}| x.a = 2|`, errorMessage("field assignment not supported HASH")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestClasses(t *testing.T) {
+	janwar := `varg Janwar {
+		karya naya(naam) { yeh.naam = naam| }
+		karya bolo() { labh yeh.naam + " bolta hai"| }
+		karya parichay() { labh "main " + yeh.bolo()| }
+	}
+	varg Kutta(Janwar) {
+		karya naya(naam, nasl) { mool.naya(naam)| yeh.nasl = nasl| }
+		karya bolo() { labh mool.bolo() + " bhau"| }
+	}
+	varg Pilla(Kutta) {
+		karya bolo() { labh mool.bolo() + " ku"| }
+	}
+	`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{janwar + `Janwar("Moti").bolo()`, "Moti bolta hai"},
+		{janwar + `Kutta("Tommy", "desi").bolo()`, "Tommy bolta hai bhau"},
+		{janwar + `Pilla("Chhotu", "desi").bolo()`, "Chhotu bolta hai bhau ku"},
+		{janwar + `Pilla("Chhotu", "desi").parichay()`, "main Chhotu bolta hai bhau ku"},
+		{janwar + `Kutta("Tommy", "desi")`, "Kutta{naam: Tommy, nasl: desi}"},
+		{janwar + `mana b = Kutta("Tommy", "desi").bolo| b()`, "Tommy bolta hai bhau"},
+		{janwar + `mana k = Kutta("Tommy", "desi")| k.naam = "Sheru"| k.bolo()`, "Sheru bolta hai bhau"},
+		{janwar + `Kutta`, "varg Kutta(Janwar)"},
+		{janwar + `mana a = Janwar("Moti")| a == a`, true},
+		{janwar + `Janwar("Moti") == Janwar("Moti")`, false},
+		{`varg Ginti { karya badhao() { yeh.n += 1| labh yeh| } }| mana g = Ginti()| g.n = 5| g.badhao().badhao().n`, 7},
+		{`वर्ग Bindu { karya नया(x) { यह.x = x| } }| Bindu(3).x`, 3},
+		{`varg A { karya f() { labh 1| } }| varg B(A) { karya f() { labh mool.f() + 1| } }| B().f()`, 2},
+		{janwar + `Janwar("Moti").umar`, errorMessage("Janwar has no field or method: umar")},
+		{janwar + `Janwar("Moti", "desi")`, errorMessage("wrong number of arguments to `naya`. got=2, want=1")},
+		{`varg A {}| A(1)`, errorMessage("wrong number of arguments to `A`. got=1, want=0")},
+		{`varg A {}| mana a = A()| a.n += 1|`, errorMessage("A has no field: n")},
+		{`varg A { karya f() { mool.f()| } }| A().f()`, errorMessage("identifier not found: mool")},
+		{`varg A {}| varg B(A) { karya f() { mool.g()| } }| B().f()`, errorMessage("A has no method: g")},
+		{`mana x = 5| varg A(x) {}`, errorMessage("varg A cannot inherit from INTEGER")},
+		{`yeh`, errorMessage("identifier not found: yeh")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestOperatorOverloading(t *testing.T) {
+	vec := `dhancha Vec {
+		x, y
+		karya __jod__(b) { labh Vec(yeh.x + b.x, yeh.y + b.y)| }
+		karya __ghatao__(b) { labh Vec(yeh.x - b.x, yeh.y - b.y)| }
+		karya __guna__(k) { labh Vec(yeh.x * k, yeh.y * k)| }
+		karya __ulta__() { labh Vec(-yeh.x, -yeh.y)| }
+		karya __barabar__(b) { labh yeh.x == b.x| }
+	}
+	`
+	bhinn := `varg Bhinn {
+		karya naya(a, b) { yeh.a = a| yeh.b = b| }
+		karya __bhag__(o) { labh Bhinn(yeh.a * o.b, yeh.b * o.a)| }
+		karya __shesh__(n) { labh yeh.a % n| }
+		karya __tulna__(o) { labh yeh.a * o.b - o.a * yeh.b| }
+	}
+	`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{vec + `Vec(1, 2) + Vec(3, 4)`, "Vec{x: 4, y: 6}"},
+		{vec + `Vec(1, 2) - Vec(3, 4)`, "Vec{x: -2, y: -2}"},
+		{vec + `Vec(1, 2) * 3`, "Vec{x: 3, y: 6}"},
+		{vec + `-Vec(1, 2)`, "Vec{x: -1, y: -2}"},
+		{vec + `mana v = Vec(1, 1)| v += Vec(2, 2)| v`, "Vec{x: 3, y: 3}"},
+		{vec + `mana v = Vec(1, 1)| v *= 5| v.y`, 5},
+		{vec + `Vec(1, 2) == Vec(1, 5)`, true},
+		{vec + `Vec(1, 2) != Vec(1, 5)`, false},
+		{vec + `Vec(1, 2) != Vec(2, 2)`, true},
+		{vec + `Vec(1, 2) mein [Vec(1, 9)]`, true},
+		{vec + `Vec(1, 2) mein (Vec(3, 4), Vec(1, 0))`, true},
+		{vec + `Vec(1, 2) mein [Vec(2, 2)]`, false},
+		{vec + `milao (Vec(1, 2)) { Vec(2, 2) => "do", Vec(1, 7) => "ek", _ => "koi nahi" }`, "ek"},
+		{bhinn + `Bhinn(1, 2) < Bhinn(2, 3)`, true},
+		{bhinn + `Bhinn(1, 2) >= Bhinn(2, 4)`, true},
+		{bhinn + `Bhinn(1, 2) > Bhinn(2, 4)`, false},
+		{bhinn + `mana q = Bhinn(1, 2) / Bhinn(3, 4)| [q.a, q.b]`, "[4, 6]"},
+		{bhinn + `Bhinn(7, 2) % 4`, 3},
+		{vec + `Vec(1, 2) / 2`, errorMessage("type mismatch: RECORD / INTEGER")},
+		{vec + `Vec(1, 2) < Vec(3, 4)`, errorMessage("unknown operator: RECORD < RECORD")},
+		{`varg A { karya __tulna__(b) { labh "kam"| } }| A() < A()`, errorMessage("__tulna__ must return INTEGER, got STRING")},
+		{`varg A { karya __tulna__(b) { labh -(2 ** 64)| } }| A() < A()`, true},
+		{`varg A { karya __barabar__(b) { labh b.x| } }| A() mein [1]`, errorMessage("field access not supported INTEGER")},
+		{`varg A { karya __barabar__(b) { labh b.x| } }| milao (A()) { 1 => 1, _ => 2 }`, errorMessage("field access not supported INTEGER")},
+		{`varg A { karya __jod__(b) { labh b.x| } }| A() + 1`, errorMessage("field access not supported INTEGER")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestEnums(t *testing.T) {
+	rang := "ganana Rang { Lal, Hara, Neela }| "
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{rang + "Rang.Hara", "Rang.Hara"},
+		{rang + "Rang", "ganana Rang { Lal, Hara, Neela }"},
+		{rang + "Rang.Lal == Rang.Lal", true},
+		{rang + "Rang.Lal == Rang.Hara", false},
+		{rang + "ganana Phal { Lal }| Rang.Lal == Phal.Lal", false},
+		{rang + "mana h = {Rang.Lal: 1, Rang.Neela: 3}| h[Rang.Neela]", 3},
+		{rang + "ganana Phal { Lal }| mana h = {Rang.Lal: 1, Phal.Lal: 2}| [h[Rang.Lal], h[Phal.Lal]]", "[1, 2]"},
+		{rang + "mana naam = []| har (r mein Rang) { naam = naam.push(r)| }| naam", "[Rang.Lal, Rang.Hara, Rang.Neela]"},
+		{rang + "lambai(Rang)", 3},
+		{rang + "Rang.Lal mein [Rang.Hara, Rang.Lal]", true},
+		{"गणना Disha { Uttar, Dakshin }| Disha.Dakshin", "Disha.Dakshin"},
+		{rang + "Rang.Peela", errorMessage("Rang has no variant: Peela")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestMatchExpressions(t *testing.T) {
+	batti := `ganana Batti { Lal, Peeli, Hari }|
+	karya agla(b) {
+		milao (b) {
+			Batti.Lal => Batti.Hari,
+			Batti.Hari => Batti.Peeli,
+			Batti.Peeli => { mana x = Batti.Lal| x }
+		}
+	}
+	`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{batti + "agla(Batti.Lal)", "Batti.Hari"},
+		{batti + "agla(agla(Batti.Lal))", "Batti.Peeli"},
+		{batti + "agla(Batti.Peeli)", "Batti.Lal"},
+		{`milao (3) { 1 => "ek", 3 => "teen", _ => "bahut" }`, "teen"},
+		{`milao (7) { 1 => "ek", 3 => "teen", _ => "bahut" }`, "bahut"},
+		{`milao ([1, 2]) { [1, 2] => "jodi", _ => "kuch aur" }`, "jodi"},
+		{`mana n = 2| milao ("do") { "ek" => 1, "do" => n * 10 }`, 20},
+		{`karya f(x) { milao (x) { 1 => { labh "ek"| }, _ => 0 }| labh "baad"| }| f(1)`, "ek"},
+		{`milao (5) { 1 => "ek" }`, errorMessage("no milao arm matches 5")},
+		{`milao (1) { x => 1 }`, errorMessage("identifier not found: x")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestMethodCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"abc".lambai()`, 3},
+		{`"नमस्ते".lambai()`, 6},
+		{`"Namaste".bada()`, "NAMASTE"},
+		{`"Namaste".chhota()`, "namaste"},
+		{`"a,b,c".todo(",")`, "[a, b, c]"},
+		{`[1, 2, 3].push(4)`, "[1, 2, 3, 4]"},
+		{`mana arr = [1, 2, 3]| arr.push(4).lambai()`, 4},
+		{`mana arr = [1, 2, 3]| arr.pop()`, "[1, 2]"},
+		{`[1, 2, 3].pehla() + [1, 2, 3].aakhri()`, 4},
+		{`[1, 2, 3].jodo(" + ")`, "1 + 2 + 3"},
+		{`(1..10).baaki().pehla()`, 2},
+		{`{"a": 1}.keys()`, "[a]"},
+		{`{"a": 1}.values()`, "[1]"},
+		{`{"a": 1, "b": 2}.lambai()`, 2},
+		{`2.345d.golai(2)`, "2.35"},
+		{`mana f = "abc".lambai| f()`, 3},
+		{`"abc".lambai`, "method lambai of STRING"},
+		{`lambai("abc")`, 3},
+		{`"abc".ulta()`, errorMessage("STRING has no method: ulta")},
+		{`5.lambai()`, errorMessage("field access not supported INTEGER")},
+		{`"a".todo()`, errorMessage("wrong number of arguments. got=0, want=1")},
+		{`"abc".lambai(1)`, errorMessage("wrong number of arguments. got=1, want=0")},
+		{`[1].push()`, errorMessage("wrong number of arguments. got=0, want=1")},
+		{`[1].push(2)`, "[1, 2]"},
+		{`2.345d.golai()`, errorMessage("wrong number of arguments. got=0, want=1 or 2")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+// marks an expected result in a mixed table as the message of an error
+type errorMessage string
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`print(1, "do", 3.5d)`, "1 do 3.5 \n"},
+		{`print({"a": 1, "b": [2]})`, "{a: 1, b: [2]} \n"},
+		{`print(karya(x) { x })`, "karya(x) { x } \n"},
+		{`print(lambai)`, "builtin function \n"},
+		{`karya f() { mana a = 1| }| print(f())`, "null \n"},
+	}
+
+	for _, tt := range tests {
+		stdout := []string{}
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		Eval(program, object.NewEnvironment(), &stdout)
+
+		if len(stdout) != 1 || stdout[0] != tt.expected {
+			t.Errorf("wrong output for %q. expected=%q, got=%q", tt.input, tt.expected, stdout)
+		}
+	}
+}
+
 func TestStringLiteral(t *testing.T) {
 	input := `"namaste duniya!"`
 
@@ -354,6 +901,21 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`lambai("")`, 0},
 		{`lambai("panch")`, 5},
 		{`lambai("namaste")`, 7},
+		{`lambai("नमस्ते")`, 6},
+		{`lambai(1..10)`, 10},
+		{`lambai((1..10)[::4])`, 3},
+		{`pehla(3..1000000000000)`, 3},
+		{`aakhri(3..1000000000000)`, 1000000000000},
+		{`pehla(baaki(3..5))`, 4},
+		{`tulna("aam", "kela")`, -1},
+		{`tulna(2, 1)`, 1},
+		{"tulna(\"\u0958लम\", \"खत\")", 1},
+		{"tulna(\"\u0958लम\", \"खत\", \"varnamala\")", -1},
+		{"tulna([\"\u0958लम\"], [\"खत\"], \"varnamala\")", -1},
+		{`tulna("ज़मीन", "ज़मीन", "वर्णमाला")`, 0},
+		{`tulna("a", "b", "klingon")`, "unknown collation: klingon"},
+		{`tulna("a", 1)`, "cannot order a and 1"},
+		{`tulna("a")`, "wrong number of arguments. got=1, want=2 or 3"},
 		{`lambai(1)`, "argument to `lambai` not supported, got INTEGER"},
 		{`lambai("ek", "do")`, "wrong number of arguments. got=2, want=1"},
 	}
@@ -442,8 +1004,20 @@ func TestArrayIndexExpressions(t *testing.T) {
 		},
 		{
 			"[1, 2, 3][-1]",
+			3,
+		},
+		{
+			"[1, 2, 3][-3]",
+			1,
+		},
+		{
+			"[1, 2, 3][-4]",
 			nil,
 		},
+		{
+			"(1..10)[-1]",
+			10,
+		},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
@@ -456,6 +1030,126 @@ func TestArrayIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2, 3, 4, 5][1:3]", "[2, 3]"},
+		{"[1, 2, 3, 4, 5][:3]", "[1, 2, 3]"},
+		{"[1, 2, 3, 4, 5][2:]", "[3, 4, 5]"},
+		{"[1, 2, 3, 4, 5][-2:]", "[4, 5]"},
+		{"[1, 2, 3, 4, 5][:-2]", "[1, 2, 3]"},
+		{"[1, 2, 3, 4, 5][::2]", "[1, 3, 5]"},
+		{"[1, 2, 3, 4, 5][::-1]", "[5, 4, 3, 2, 1]"},
+		{"[1, 2, 3, 4, 5][3:0:-1]", "[4, 3, 2]"},
+		{"[1, 2, 3, 4, 5][1:100]", "[2, 3, 4, 5]"},
+		{"[1, 2, 3, 4, 5][4:1]", "[]"},
+		{"[1, 2, 3][:]", "[1, 2, 3]"},
+		{`"namaste"[1:4]`, "ama"},
+		{`"नमस्ते"[:2]`, "नम"},
+		{`"नमस्ते"[-1]`, "े"},
+		{`"abc"[::-1]`, "cba"},
+		{"1..5", "1..5"},
+		{"1..<5", "1..4"},
+		{"5..1", "5..<5"},
+		{"(1..10)[::3]", "(1..10)[::3]"},
+		{"(1..10)[::-2]", "(2..10)[::-2]"},
+		{"(1..10)[2:5]", "3..5"},
+		{"[1, 2, 3][::9223372036854775807]", "[1]"},
+		{"[1, 2, 3][::-9223372036854775807]", "[3]"},
+		{"(1..10)[::9223372036854775807]", "1..1"},
+		{"9223372036854775806..9223372036854775807", "9223372036854775806..9223372036854775807"},
+		{"lambai(1..9223372036854775807)", "9223372036854775807"},
+		{"lambai(-9223372036854775807..-1)", "9223372036854775807"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestSliceErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`[1, 2, 3][1:"x"]`, "slice indices must be INTEGER, got STRING"},
+		{"[1, 2, 3][::0]", "slice step cannot be zero"},
+		{"5[1:2]", "slice operator not supported INTEGER"},
+		{"har (x mein 5) { x }", "cannot iterate over INTEGER"},
+		{"0..9223372036854775807", "range too long: 0 .. 9223372036854775807"},
+		{"lambai(-9223372036854775807..9223372036854775806)", "range too long: -9223372036854775807 .. 9223372036854775806"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestForEachExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"mana s = 0| har (x mein [1, 2, 3]) { s += x| }| s", 6},
+		{"mana s = 0| har (i mein 1..100) { s += i| }| s", 5050},
+		{"mana s = 0| har (i mein 1..<100) { s += i| }| s", 4950},
+		{"mana n = 0| har (c mein \"नमस्ते\") { n += 1| }| n", 6},
+		{"mana s = 0| har ([a, b] mein [[1, 2], [3, 4]]) { s += a * b| }| s", 14},
+		{"mana f = karya() { har (i mein 1..1000000000000) { agar (i == 7) { labh i| } } }| f()", 7},
+		{"mana s = 0| har (k mein {1: 10, 2: 20}) { s += k| }| s", 3},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestMembershipExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"2 mein [1, 2, 3]", true},
+		{"4 mein [1, 2, 3]", false},
+		{"[1, 2] mein [[1, 2], [3]]", true},
+		{"[2, 1] mein [[1, 2], [3]]", false},
+		{`{"a": [1]} mein [{"a": [1]}]`, true},
+		{`"2" mein [1, 2, 3]`, false},
+		{`"ste" mein "namaste"`, true},
+		{`"मस्" में "नमस्ते"`, true},
+		{`"xyz" mein "namaste"`, false},
+		{`"ek" mein {"ek": 1, "do": 2}`, true},
+		{`1 mein {"ek": 1}`, false},
+		{"5 mein 1..10", true},
+		{"10 mein 1..<10", false},
+		{"4 mein (1..10)[::3]", true},
+		{"5 mein (1..10)[::3]", false},
+		{"!(5 mein [1, 2])", true},
+		{`1 mein "abc"`, errorMessage("`mein` on a STRING needs a STRING or CHAR, got INTEGER")},
+		{"1 mein 5", errorMessage("`mein` not supported for INTEGER")},
+		{"{} mein {}", errorMessage("unusable as hash key: HASH")},
+		{"[{}] mein {}", errorMessage("unusable as hash key: ARRAY")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
 func TestHashLiterals(t *testing.T) {
 	input := `mana do = "do"|
 	{
@@ -471,23 +1165,435 @@ func TestHashLiterals(t *testing.T) {
 	if !ok {
 		t.Fatalf("Eval didn't return Hash. got=%T (%+v)", evaluated, evaluated)
 	}
-	expected := map[object.HashKey]int64{
-		(&object.String{Value: "ek"}).HashKey():   1,
-		(&object.String{Value: "do"}).HashKey():   2,
-		(&object.String{Value: "teen"}).HashKey(): 3,
-		(&object.Integer{Value: 4}).HashKey():     4,
-		TRUE.HashKey():                            5,
-		FALSE.HashKey():                           6,
+	expected := []struct {
+		key   object.Object
+		value int64
+	}{
+		{&object.String{Value: "ek"}, 1},
+		{&object.String{Value: "do"}, 2},
+		{&object.String{Value: "teen"}, 3},
+		{&object.Integer{Value: 4}, 4},
+		{TRUE, 5},
+		{FALSE, 6},
 	}
-	if len(result.Pairs) != len(expected) {
-		t.Fatalf("Hash has wrong num of pairs. got=%d", len(result.Pairs))
+	if result.Len() != len(expected) {
+		t.Fatalf("Hash has wrong num of pairs. got=%d", result.Len())
 	}
-	for expectedKey, expectedValue := range expected {
-		pair, ok := result.Pairs[expectedKey]
+	for _, tt := range expected {
+		pair, ok := result.Get(tt.key)
 		if !ok {
-			t.Errorf("no pair for given key in Pairs")
+			t.Errorf("no pair for key %s in Pairs", tt.key.Inspect())
+			continue
 		}
-		testIntegerObject(t, pair.Value, expectedValue)
+		testIntegerObject(t, pair.Value, tt.value)
+	}
+}
+
+func TestHashOrder(t *testing.T) {
+	ginti := `varg Ginti { karya agla() { yeh.n += 1| labh yeh.n| } }| mana g = Ginti()| g.n = 0| `
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"teen": 3, "ek": 1, "do": 2}`, "{teen: 3, ek: 1, do: 2}"},
+		{`{"b": 1, "a": 2, "b": 3}`, "{b: 3, a: 2}"},
+		{`{"z": 1, "y": 2, "x": 3}.keys()`, "[z, y, x]"},
+		{`{"z": 1, "y": 2, "x": 3}.values()`, "[1, 2, 3]"},
+		{`mana k = []| har (key mein {3: 0, 1: 0, 2: 0}) { k = k.push(key)| }| k`, "[3, 1, 2]"},
+		{ginti + `{"a": g.agla(), "b": g.agla(), "c": g.agla()}`, "{a: 1, b: 2, c: 3}"},
+		{ginti + `{g.agla(): "a", g.agla(): "b"}`, "{1: a, 2: b}"},
+		{`mana {"b": b, "a": a} = {"a": 1, "b": 2}| [a, b]`, "[1, 2]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestStructuralHashKeys(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{[1, 2]: "x"}[[1, 2]]`, "x"},
+		{`{[1, 2]: "x"}[[2, 1]]`, nil},
+		{`{[1, [2, 3]]: "x"}[[1, [2, 3]]]`, "x"},
+		{`{[1, 2]: "x", [1, 2]: "y"}`, "{[1, 2]: y}"},
+		{`[1, 2] mein {[1, 2]: 0}`, true},
+		{`{1: "x", 1.0d: "y"}`, "{1: y}"},
+		{`{1.5d: "x"}[1.50d]`, "x"},
+		{`{1d: "x"}[1]`, "x"},
+		{`{2 ** 64: "x"}[(2 ** 64) * 1.0d]`, "x"},
+		{`dhancha Bindu { x, y }| {Bindu(1, 2): "ghar"}[Bindu(1, 2)]`, "ghar"},
+		{`dhancha Bindu { x, y }| {Bindu(1, 2): "ghar"}[Bindu(2, 1)]`, nil},
+		{`dhancha Bindu { x, y }| Bindu(1, 2) mein {Bindu(1, 2)}`, true},
+		{`dhancha Bindu { x, y }| mana p = Bindu(1, 2)| mana h = {p: 1}| p.x = 5|`, errorMessage("cannot assign Bindu.x: it is a hash key or set element")},
+		{`dhancha Bindu { x, y }| mana p = Bindu(1, 2)| mana s = {[p]}| p.y += 1|`, errorMessage("cannot assign Bindu.y: it is a hash key or set element")},
+		{`dhancha Bindu { x, y }| dhancha Rekha { a, b }| mana p = Bindu(1, 2)| mana h = {Rekha(p, p): 1}| p.x = 0|`, errorMessage("cannot assign Bindu.x: it is a hash key or set element")},
+		{`dhancha Bindu { x, y }| mana p = Bindu(1, 2)| mana h = {Bindu(1, 2): 1}| h[p]| p.x = 5| p.x`, 5},
+		{`dhancha Dibba { h }| {Dibba({}): 1}`, errorMessage("unusable as hash key: RECORD")},
+		{`dhancha Bindu { x, y }| Bindu(1, 2) == Bindu(1, 2)`, true},
+		{`ganana Rang { Lal }| {[Rang.Lal, 1]: "x"}[[Rang.Lal, 1]]`, "x"},
+		{`{[1, {}]: 1}`, errorMessage("unusable as hash key: ARRAY")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestSets(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{3, 1, 2, 1}`, "{3, 1, 2}"},
+		{`{1, 2} + {2, 3}`, "{1, 2, 3}"},
+		{`{1, 2, 3} & {2, 3, 4}`, "{2, 3}"},
+		{`{1, 2, 3} - {2}`, "{1, 3}"},
+		{`{1, 2} ^ {2, 3}`, "{1, 3}"},
+		{`{1, 2} == {2, 1}`, true},
+		{`{1, 2} != {1}`, true},
+		{`2 mein {1, 2}`, true},
+		{`5 mein {1, 2}`, false},
+		{`[1, 2] mein {[1, 2], [3]}`, true},
+		{`lambai({"a", "b", "a"})`, 2},
+		{`{1, 2, 3}.lambai()`, 3},
+		{`samuchchay()`, "samuchchay()"},
+		{`samuchchay([1, 1, 2])`, "{1, 2}"},
+		{`{1, 1.0d, 2 ** 64, (2 ** 64) * 1d}`, "{1, 18446744073709551616}"},
+		{`samuchchay("aba")`, "{a, b}"},
+		{`samuchchay(1..<4) & {0, 2, 4}`, "{2}"},
+		{`mana kul = 0| har (x mein {1, 2, 3}) { kul += x| }| kul`, 6},
+		{`{1, 2} * {2}`, errorMessage("unknown operator: SET * SET")},
+		{`{1, {}}`, errorMessage("unusable as set element: HASH")},
+		{`{} mein {1}`, errorMessage("unusable as set element: HASH")},
+		{`samuchchay([{}])`, errorMessage("unusable as set element: HASH")},
+		{`samuchchay(5)`, errorMessage("cannot iterate over INTEGER")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestComprehensions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`[x * x har x mein [1, 2, 3]]`, "[1, 4, 9]"},
+		{`[x * x har x mein 1..6 agar x % 2 == 0]`, "[4, 16, 36]"},
+		{`[(a, b) har a mein 1..3 har b mein 1..3 agar a < b]`, "[(1, 2), (1, 3), (2, 3)]"},
+		{`[x har x mein 1..10 agar x > 2 agar x < 5]`, "[3, 4]"},
+		{`[a * b har [a, b] mein [[1, 2], [3, 4]]]`, "[2, 12]"},
+		{`[c har c mein "abc"]`, "[a, b, c]"},
+		{`[x har x mein []]`, "[]"},
+		{`{x % 3 har x mein 1..7}`, "{1, 2, 0}"},
+		{`{c har c mein "banana"}`, "{b, a, n}"},
+		{`mana h = {k: k * k har k mein [1, 2, 3]}| h[3]`, 9},
+		{`{k: 0 har k mein ["a", "b", "a"]}.lambai()`, 2},
+		{`mana x = 100| [x har x mein [1, 2]]| x`, 100},
+		{`[x har x mein [1, 2]]| x`, errorMessage("identifier not found: x")},
+		{`mana n = 10| [x + n har x mein [1, 2]]`, "[11, 12]"},
+		{`[x har x mein 5]`, errorMessage("cannot iterate over INTEGER")},
+		{`[x / 0 har x mein [1]]`, errorMessage("division by zero")},
+		{`[x har x mein [1] agar y]`, errorMessage("identifier not found: y")},
+		{`{[x] har x mein [{}]}`, errorMessage("unusable as set element: ARRAY")},
+		{`{x: 1 har x mein [{}]}`, errorMessage("unusable as hash key: HASH")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestGenerators(t *testing.T) {
+	ginti := `karya ginti(n) {
+		mana i = 0
+		jabtak (i < n) {
+			upaj i
+			i += 1
+		}
+	}
+	`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{ginti + `ginti(3)`, "generator ginti"},
+		{`(() => { upaj 1| })()`, "generator"},
+		{ginti + `mana g = ginti(2)| [agla(g), agla(g)]`, "[0, 1]"},
+		{ginti + `mana g = ginti(1)| agla(g)| agla(g, -1)`, -1},
+		{ginti + `mana g = ginti(0)| agla(g)`, errorMessage("generator ginti is exhausted")},
+		{ginti + `mana s = 0| har (x mein ginti(5)) { s += x| }| s`, 10},
+		{ginti + `[x * x har x mein ginti(6) agar x % 2 == 0]`, "[0, 4, 16]"},
+		{ginti + `samuchchay(ginti(3))`, "{0, 1, 2}"},
+		{ginti + `mana g = ginti(3)| [x har x mein g]| [x har x mein g]`, "[]"},
+		{`karya nat() { mana n = 1| jabtak (satya) { upaj n| n += 1| } }| mana g = nat()| [agla(g) har _ mein 1..5]`, "[1, 2, 3, 4, 5]"},
+		{`karya f() { upaj 1| labh 5| upaj 2| }| [x har x mein f()]`, "[1]"},
+		{`karya f() { upaj 1| upaj 1 / 0| }| mana g = f()| agla(g)| agla(g)`, errorMessage("division by zero")},
+		{`karya f() { upaj 1| phenko "ruko"| }| [x har x mein f()]`, errorMessage("ruko")},
+		{`karya bahar() { karya andar() { labh 1| }| upaj andar()| }| agla(bahar())`, 1},
+		{`agla([1, 2])`, errorMessage("argument to `agla` must be GENERATOR, got ARRAY")},
+		{`dhancha Jodi {
+			a, b
+			karya __iter__() { labh [yeh.a, yeh.b]| }
+		}
+		[x * 10 har x mein Jodi(1, 2)]`, "[10, 20]"},
+		{`varg Ulta {
+			karya naya(xs) { yeh.xs = xs| }
+			karya __iter__() {
+				mana i = lambai(yeh.xs) - 1
+				jabtak (i >= 0) { upaj yeh.xs[i]| i -= 1| }
+			}
+		}
+		[x har x mein Ulta([1, 2, 3])]`, "[3, 2, 1]"},
+		{`varg A { karya __iter__() { labh 5| } }| har (x mein A()) {}`, errorMessage("__iter__ must return an iterable, got INTEGER")},
+		{`varg A {}| har (x mein A()) {}`, errorMessage("cannot iterate over INSTANCE")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+// what a generator prints reaches whoever resumes it, even from a later
+// program run in the same environment as the REPL does, and a generator a
+// loop leaves midway is closed, running its aakhir blocks
+func TestGeneratorOutput(t *testing.T) {
+	same := func(a, b []string) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	tests := []struct {
+		programs []string
+		expected [][]string
+	}{
+		{
+			[]string{
+				`karya f() { print("ek")| upaj 1| print("do")| upaj 2| }| mana g = f()| agla(g)`,
+				`agla(g)`,
+			},
+			[][]string{{"ek \n"}, {"do \n"}},
+		},
+		{
+			[]string{`karya f() { koshish { upaj 1| upaj 2| } aakhir { print("band")| } }
+			karya pehla() { har (x mein f()) { labh x| } }
+			pehla()| print("baad")`},
+			[][]string{{"band \n", "baad \n"}},
+		},
+		{
+			[]string{`karya f() { koshish { upaj 1| upaj 2| } aakhir { print("band")| } }
+			har (x mein f()) { 1 / 0| }`},
+			[][]string{{"band \n"}},
+		},
+		{
+			[]string{`karya f() { koshish { upaj 1| upaj 2| } aakhir { print("band")| } }
+			mana g = f()| agla(g)| print("baad")`},
+			[][]string{{"baad \n"}},
+		},
+	}
+
+	for _, tt := range tests {
+		env := object.NewEnvironment()
+		for i, input := range tt.programs {
+			stdout := []string{}
+			program := parser.New(lexer.New(input)).ParseProgram()
+			Eval(program, env, &stdout)
+
+			if !same(stdout, tt.expected[i]) {
+				t.Errorf("wrong output for %q. expected=%q, got=%q", input, tt.expected[i], stdout)
+			}
+		}
+	}
+
+	// generators nothing iterated to the end are closed once the program is;
+	// pakdo can't stop that, and an upaj after it ends the body at once
+	closing := []struct {
+		input    string
+		expected []string
+	}{
+		{`karya f() { koshish { upaj 1| upaj 2| } aakhir { print("band")| } }
+		mana g = f()| agla(g)| agla(f())| f()`, []string{"band \n", "band \n"}},
+		{`karya f() { jabtak (satya) { koshish { upaj 1| } pakdo (e) { print("pakda")| } } }
+		mana g = f()| agla(g)`, []string{}},
+		{`karya f() { koshish { upaj 1| } aakhir { print("ek")| upaj 2| print("do")| } }
+		mana g = f()| agla(g)`, []string{"ek \n"}},
+	}
+
+	for _, tt := range closing {
+		env := object.NewEnvironment()
+		stdout := []string{}
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		Eval(program, env, &stdout)
+		env.CloseGenerators(&stdout)
+
+		if !same(stdout, tt.expected) {
+			t.Errorf("wrong output closing generators in %q. expected=%q, got=%q", tt.input, tt.expected, stdout)
+		}
+	}
+}
+
+func TestTuples(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`(1, "a")`, "(1, a)"},
+		{`(1,)`, "(1,)"},
+		{`()`, "()"},
+		{`divmod(7, 2)`, "(3, 1)"},
+		{`divmod(-7, 2)`, "(-4, 1)"},
+		{`divmod(7, -2)`, "(-4, -1)"},
+		{`divmod(-7, -2)`, "(3, -1)"},
+		{`divmod(-7, 2) == (-7 // 2, -7 % 2)`, "satya"},
+		{`divmod(7, -2) == (7 // -2, 7 % -2)`, "satya"},
+		{`divmod(-(2 ** 64), 7) == (-(2 ** 64) // 7, -(2 ** 64) % 7)`, "satya"},
+		{`mana q, r = divmod(7, 2)| q * 10 + r`, 31},
+		{`mana (a, [b, c]) = (1, [2, 3])| a + b + c`, 6},
+		{`mana a, b = [1, 2]| a - b`, -1},
+		{`mana jodi = karya(x) { labh x, x * x| }| mana a, b = jodi(3)| b - a`, 6},
+		{`mana t = 1, 2| t`, "(1, 2)"},
+		{`(4, 5, 6)[1]`, 5},
+		{`(4, 5, 6)[1:]`, "(5, 6)"},
+		{`lambai((1, 2, 3))`, 3},
+		{`(1, 2).lambai()`, 2},
+		{`2 mein (1, 2)`, true},
+		{`(1, 2) == (1, 2)`, true},
+		{`(1, 2) == [1, 2]`, false},
+		{`(1, 2) < (1, 3)`, true},
+		{`(2,) > (1, 9)`, true},
+		{`{(1, 2): "x"}[(1, 2)]`, "x"},
+		{`{(1, 2), (1, 2)}`, "{(1, 2)}"},
+		{`mana kul = 0| har (x mein (1, 2, 3)) { kul += x| }| kul`, 6},
+		{`mana a, b = (1, 2, 3)`, errorMessage("wrong number of values to destructure. got=3, want=2")},
+		{`mana a, b = 5`, errorMessage("cannot destructure INTEGER as TUPLE")},
+		{`divmod(1, 0)`, errorMessage("division by zero")},
+		{`divmod(1.5d, 2)`, errorMessage("arguments to `divmod` must be INTEGER, got DECIMAL")},
+		{`{(1, {}): 2}`, errorMessage("unusable as hash key: TUPLE")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestPipeExpressions(t *testing.T) {
+	helpers := `
+mana filter = karya(arr, f) {
+	mana result = []|
+	har (x mein arr) { agar (f(x)) { result = result.push(x)| } }
+	result
+}|
+mana map = karya(arr, f) {
+	mana result = []|
+	har (x mein arr) { result = result.push(f(x))| }
+	result
+}|
+mana jod = karya(arr) { mana kul = 0| har (x mein arr) { kul += x| } kul }|
+mana even = (n) => n % 2 == 0|
+mana double = (n) => n * 2|
+`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{helpers + `[1, 2, 3, 4] |> filter(even) |> map(double) |> jod`, 12},
+		{helpers + `1..5 |> map((n) => n * n)`, "[1, 4, 9, 16, 25]"},
+		{`"abc" |> lambai`, 3},
+		{`"abc" |> lambai == 3`, true},
+		{`[3, 4] |> push(5)`, "[3, 4, 5]"},
+		{`"amrit" |> ((s) => s.bada())`, "AMRIT"},
+		{`mana n = 4 |> ((x) => x + 1)| n`, 5},
+		{`5 |> 6`, errorMessage("not a function: INTEGER")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestArrowFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`mana double = (x) => x * 2| double(4)`, 8},
+		{`mana inc = x => x + 1| inc(1)`, 2},
+		{`((a, b) => a * b)(3, 4)`, 12},
+		{`(() => 7)()`, 7},
+		{`mana add = x => y => x + y| add(2)(3)`, 5},
+		{`mana f = (x) => { mana y = x * x| y + 1 }| f(3)`, 10},
+		{`mana f = (a, b = 10) => a + b| f(1)`, 11},
+		{`mana f = ([a, b]) => a - b| f([5, 2])`, 3},
+		{`mana lagao = karya(f, x) { f(x) }| lagao((n) => n * 10, 4)`, 40},
+		{`mana n = 3| mana guna = (x) => x * n| guna(2)`, 6},
+		{`(x) => x * 2`, "karya(x) { (x * 2) }"},
+		{`mana f = (x) => x| f()`, errorMessage("wrong number of arguments to `f`. got=0, want=1")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestChars(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`'a'`, "a"},
+		{`"abc"[1] == 'b'`, true},
+		{`"abc"[1] == "b"`, false},
+		{`'a' < 'b'`, true},
+		{`'a' + 'b'`, "ab"},
+		{`"a" + 'b' + "c"`, "abc"},
+		{`'क' mein "कमल"`, true},
+		{`{'a': 1}["abc"[0]]`, 1},
+		{`kood('A')`, 65},
+		{`'क'.kood()`, 0x915},
+		{`akshar(2325)`, "क"},
+		{`akshar(kood('a') + 1)`, "b"},
+		{`svar_hai('आ')`, true},
+		{`svar_hai('क')`, false},
+		{`vyanjan_hai('क')`, true},
+		{`vyanjan_hai('ा')`, false},
+		{`'ा'.matra_hai()`, true},
+		{`'्'.halant_hai()`, true},
+		{`'a'.halant_hai()`, false},
+		{`mana ginti = 0| har (c mein "किताब") { agar (c.matra_hai()) { ginti += 1| } }| ginti`, 2},
+		{`kood("a")`, errorMessage("argument to `kood` must be CHAR, got STRING")},
+		{`akshar(-1)`, errorMessage("not a valid code point: -1")},
+		{`akshar(55296)`, errorMessage("not a valid code point: 55296")},
+		{`matra_hai(1)`, errorMessage("argument to `matra_hai` must be CHAR, got INTEGER")},
+		{`'a' - 'b'`, errorMessage("unknown operator: CHAR - CHAR")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
 	}
 }
 
@@ -535,3 +1641,240 @@ func TestHashIndexExpressions(t *testing.T) {
 		}
 	}
 }
+
+func TestDestructuring(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"mana [a, b] = [1, 2]| a + b|", 3},
+		{"mana [a, b, ...rest] = [1, 2, 3, 4]| lambai(rest)|", 2},
+		{"mana [a, ...rest] = [1]| lambai(rest)|", 0},
+		{"mana [a, [b, c]] = [1, [2, 3]]| a + b + c|", 6},
+		{`mana {"naam": n, "umar": u} = {"naam": "raj", "umar": 20}| u|`, 20},
+		{`mana {"ank": [x, y]} = {"ank": [4, 5]}| x * y|`, 20},
+		{"mana add = karya([a, b]) { a + b }| add([2, 3])|", 5},
+		{`mana umar = karya({"umar": u}) { u }| umar({"umar": 7})|`, 7},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestDestructuringErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{
+			"mana [a, b] = [1]|",
+			"wrong number of values to destructure. got=1, want=2",
+		},
+		{
+			"mana [a, b] = [1, 2, 3]|",
+			"wrong number of values to destructure. got=3, want=2",
+		},
+		{
+			"mana [a, b, ...rest] = [1]|",
+			"wrong number of values to destructure. got=1, want at least 2",
+		},
+		{
+			"mana [a, b] = 5|",
+			"cannot destructure INTEGER as ARRAY",
+		},
+		{
+			`mana {"naam": n} = [1]|`,
+			"cannot destructure ARRAY as HASH",
+		},
+		{
+			`mana {"naam": n} = {"umar": 1}|`,
+			"key not found while destructuring: naam",
+		},
+		{
+			"mana f = karya([a, b]) { a }| f(1)|",
+			"cannot destructure INTEGER as ARRAY",
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestFunctionParameters(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"mana add = karya(x, y = 10) { x + y }| add(1)|", 11},
+		{"mana add = karya(x, y = 10) { x + y }| add(1, 2)|", 3},
+		{"mana add = karya(x, y = x * 2) { x + y }| add(3)|", 9},
+		{"mana count = karya(first, ...rest) { lambai(rest) }| count(1, 2, 3)|", 2},
+		{"mana count = karya(first, ...rest) { lambai(rest) }| count(1)|", 0},
+		{"mana add = karya(x, y, z) { x + y + z }| mana a = [1, 2, 3]| add(...a)|", 6},
+		{"mana add = karya(x, y, z) { x + y + z }| add(1, ...[2, 3])|", 6},
+		{"mana count = karya(...all) { lambai(all) }| count(...[1, 2], 3, ...[4])|", 4},
+		{"mana a = [2, 3]| lambai([1, ...a, 4])|", 4},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFunctionArity(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{
+			"mana add = karya(x, y) { x + y }| add(1)|",
+			"wrong number of arguments to `add`. got=1, want=2",
+		},
+		{
+			"mana add = karya(x, y) { x + y }| add(1, 2, 3)|",
+			"wrong number of arguments to `add`. got=3, want=2",
+		},
+		{
+			"mana add = karya(x, y = 1) { x + y }| add()|",
+			"wrong number of arguments to `add`. got=0, want between 1 and 2",
+		},
+		{
+			"mana add = karya(x, ...rest) { x }| add()|",
+			"wrong number of arguments to `add`. got=0, want at least 1",
+		},
+		{
+			"karya(x, y) { x }(1)|",
+			"wrong number of arguments. got=1, want=2",
+		},
+		{
+			"mana add = karya(x) { x }| add(...5)|",
+			"cannot spread INTEGER, want ARRAY",
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q",
+				tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestNamedFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"karya double(x) { x * 2 } double(4)|", 8},
+		{"mana a = double(4)| karya double(x) { x * 2 } a|", 8},
+		{`karya fact(n) {
+			agar (n < 2) { labh 1| }
+			n * fact(n - 1)|
+		}
+		fact(5)|`, 120},
+		{`karya isEven(n) { agar (n == 0) { satya } varna { isOdd(n - 1) } }
+		karya isOdd(n) { agar (n == 0) { asatya } varna { isEven(n - 1) } }
+		agar (isEven(10)) { 1 } varna { 0 }`, 1},
+		{"mana f = karya fact(n) { agar (n < 2) { 1 } varna { n * fact(n - 1) } }| f(4)|", 24},
+		{"mana outer = karya() { labh inner()| karya inner() { 7 } }| outer()|", 7},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFunctionInspect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"karya jod(a, b) { a + b } jod|", "karya jod(a, b)"},
+		{"mana jod = karya(a, b) { a + b }| jod|", "karya jod(a, b)"},
+		{"karya(a) { a + 1 }|", "karya(a) { (a + 1) }"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil {
+			t.Errorf("Eval returned nil for %q", tt.input)
+			continue
+		}
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("Inspect() wrong. expected=%q, got=%q", tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestTryCatchFinally(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`koshish { 1 } pakdo (e) { 2 }`, 1},
+		{`koshish { phenko "galat"| 1 } pakdo (e) { 2 }`, 2},
+		{`koshish { phenko "galat"| } pakdo (e) { e["sandesh"] }`, "galat"},
+		{`koshish { phenko "galat"| } pakdo (e) { e["prakar"] }`, "Error"},
+		{`koshish { 5 + satya| } pakdo (e) { e["prakar"] }`, "TypeError"},
+		{`koshish { foobar| } pakdo (e) { e["prakar"] }`, "NameError"},
+		{`koshish { 1 / 0| } pakdo (e) { e["prakar"] }`, "ZeroDivisionError"},
+		{`koshish { 1 / 0| } pakdo (e) { e.prakar }`, "ZeroDivisionError"},
+		{`koshish { phenko galti("umar galat hai", "ValueError")| } pakdo (e) { e["prakar"] }`, "ValueError"},
+		{`koshish { phenko 42| } pakdo (e) { e["mulya"] }`, 42},
+		{"koshish {\n  phenko \"galat\"|\n} pakdo (e) { e[\"pankti\"] }", 2},
+		{`koshish { phenko "galat"| } pakdo (e) { e["stambh"] }`, 11},
+		{`mana x = 0| koshish { x = 1| } aakhir { x = x + 10| } x|`, 11},
+		{`mana x = 0| koshish { phenko "a"| } pakdo { x = 1| } aakhir { x = x + 10| } x|`, 11},
+		{`mana f = karya() { koshish { labh 1| } aakhir { 2 } 3 }| f()|`, 1},
+		{`mana f = karya() { koshish { labh 1| } aakhir { labh 2| } }| f()|`, 2},
+		{`mana f = karya() { koshish { phenko "a"| } pakdo (e) { labh 5| } 6 }| f()|`, 5},
+		{`mana f = karya() { phenko "andar"| }| koshish { f()| } pakdo (e) { e["sandesh"] }`, "andar"},
+		{`koshish { koshish { phenko "a"| } pakdo (e) { phenko e| } } pakdo (e) { e["sandesh"] }`, "a"},
+		{`mana i = 0| jabtak (i < 10) { i = i + 1| agar (i == 3) { phenko "ruko"| } }| i|`, errorMessage("ruko")},
+		{`mana i = 0| koshish { jabtak (satya) { i = i + 1| agar (i == 3) { phenko "ruko"| } } } pakdo { i }`, 3},
+		{`mana f = karya() { mana i = 0| jabtak (satya) { i = i + 1| agar (i == 4) { labh i| } } }| f()|`, 4},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testExpectedObject(t, tt.input, evaluated, tt.expected)
+	}
+}
+
+func TestUncaughtErrorPosition(t *testing.T) {
+	input := `mana a = 1|
+mana b = a + satya|`
+
+	evaluated := testEval(input)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+	if errObj.Kind != object.TYPE_ERROR {
+		t.Errorf("wrong error kind. got=%q", errObj.Kind)
+	}
+	if errObj.Line != 2 || errObj.Column != 12 {
+		t.Errorf("wrong error position. expected=2:12, got=%d:%d", errObj.Line, errObj.Column)
+	}
+	expected := "ERROR: TypeError: type mismatch: INTEGER + BOOLEAN (line 2, column 12)"
+	if errObj.Inspect() != expected {
+		t.Errorf("Inspect() wrong. expected=%q, got=%q", expected, errObj.Inspect())
+	}
+}
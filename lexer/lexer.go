@@ -1,6 +1,7 @@
 package lexer
 
 import (
+	"strings"
 	"unicode/utf8"
 
 	"github.com/Suryansh-23/amrit/token"
@@ -11,17 +12,58 @@ type Lexer struct {
 	position     int
 	readPosition int
 	ch           rune
+	line         int // line of ch
+	column       int // column of ch, counted in runes
+
+	// what automatic terminators depend on: the type of the last token other
+	// than a comment, and the brackets still open, innermost last. nesting is
+	// a string so that copies of the lexer, made to look ahead, don't share it
+	last    token.TokenType
+	nesting string
+}
+
+// tokens that can end a statement, after which a line break ends it
+var endsStatement = map[token.TokenType]bool{
+	token.IDENT:       true,
+	token.INT:         true,
+	token.DECIMAL:     true,
+	token.STRING:      true,
+	token.CHAR:        true,
+	token.TRUE_LATIN:  true,
+	token.FALSE_LATIN: true,
+	token.SELF_LATIN:  true,
+	token.RPAREN:      true,
+	token.RBRACKET:    true,
+	token.RBRACE:      true,
+}
+
+// tokens that carry on the statement of the line before when they start a
+// line, as in a varna on the line after its agar block ends
+var continuesStatement = map[token.TokenType]bool{
+	token.DOT:           true,
+	token.PIPE:          true,
+	token.ARROW:         true,
+	token.LBRACE:        true,
+	token.ELSE_LATIN:    true,
+	token.CATCH_LATIN:   true,
+	token.FINALLY_LATIN: true,
 }
 
 // creates a lexer struct for parsing
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, line: 1}
 	l.readChar()
 	return l
 }
 
 // reads a single character from the program (Note - it only supports ASCII - change ch's type from byte to rune for UTF support)
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
+	l.column++
+
 	size := 0
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
@@ -39,11 +81,81 @@ func (l *Lexer) readChar() {
 	l.readPosition += size
 }
 
-// returns the subsequent token from the program string
+// returns the subsequent token from the program string. A line break, or the
+// # comment running to it, ends the statement before it with a TERM whose
+// literal is "\n", as if a | were written there, when:
+//   - the last token can end a statement: a name, a literal, satya, asatya,
+//     yeh, or a closing ), ] or }
+//   - the line isn't inside ( ) or [ ], though it may be inside { }
+//   - the next line doesn't start with ., |>, =>, {, varna, pakdo or aakhir
 func (l *Lexer) NextToken() token.Token {
+	l.skipWhitespace()
+
+	line, column := l.line, l.column
 	var tok token.Token
+	if (l.ch == '\n' || l.ch == '#') && l.atStatementEnd() {
+		tok = token.Token{Type: token.TERM, Literal: "\n"}
+		if l.ch == '\n' {
+			l.readChar()
+		}
+	} else {
+		tok = l.readToken()
+	}
+	tok.Line, tok.Column = line, column
 
-	l.skipWhitespace()
+	l.track(tok.Type)
+
+	return tok
+}
+
+// reports whether a line ending at the current character ends a statement
+func (l *Lexer) atStatementEnd() bool {
+	if !endsStatement[l.last] {
+		return false
+	}
+	if n := len(l.nesting); n > 0 && l.nesting[n-1] != '{' {
+		return false
+	}
+
+	return !continuesStatement[l.peekNextLine()]
+}
+
+// returns the type of the first token after the current line, skipping blank
+// lines and comments, without consuming anything. The parser likewise drops
+// comments inside a statement, so one before a continuing line is harmless
+func (l *Lexer) peekNextLine() token.TokenType {
+	ahead := *l
+	for {
+		for ahead.ch == ' ' || ahead.ch == '\t' || ahead.ch == '\n' || ahead.ch == '\r' {
+			ahead.readChar()
+		}
+
+		tok := ahead.readToken()
+		if tok.Type != token.SINGLE_COMMENT && tok.Type != token.MULTI_COMMENT {
+			return tok.Type
+		}
+	}
+}
+
+// records a token just read for deciding where terminators go
+func (l *Lexer) track(t token.TokenType) {
+	switch t {
+	case token.SINGLE_COMMENT, token.MULTI_COMMENT:
+		return
+	case token.LPAREN, token.LBRACKET, token.LBRACE:
+		l.nesting += string(t)
+	case token.RPAREN, token.RBRACKET, token.RBRACE:
+		if n := len(l.nesting); n > 0 {
+			l.nesting = l.nesting[:n-1]
+		}
+	}
+
+	l.last = t
+}
+
+// reads the token starting at the current character
+func (l *Lexer) readToken() token.Token {
+	var tok token.Token
 
 	// Main logic for parsing through the input string and thus generating resp. tokens
 	switch l.ch {
@@ -52,6 +164,8 @@ func (l *Lexer) NextToken() token.Token {
 			ch := l.ch
 			l.readChar()
 			tok = token.Token{Type: token.EQ, Literal: string(l.ch) + string(ch)}
+		} else if l.match(token.ARROW) {
+			tok = token.Token{Type: token.ARROW, Literal: token.ARROW}
 		} else {
 			tok = newToken(token.ASSIGN, l.ch)
 		}
@@ -80,22 +194,14 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.MINUS, l.ch)
 		}
 	case '/':
-		if l.peekChar() == '=' {
+		if l.match(token.FLOOR_DIV_EQ) {
+			tok = token.Token{Type: token.FLOOR_DIV_EQ, Literal: token.FLOOR_DIV_EQ}
+		} else if l.match(token.FLOOR_DIV) {
+			tok = token.Token{Type: token.FLOOR_DIV, Literal: token.FLOOR_DIV}
+		} else if l.peekChar() == '=' {
 			ch := l.ch
 			l.readChar()
 			tok = token.Token{Type: token.SLASH_EQ, Literal: string(ch) + string(l.ch)}
-		} else if l.peekChar() == '/' {
-			l.readChar()
-			tok.Type = token.SINGLE_COMMENT
-			tok.Literal = "/"
-
-			//ignore the rest of the line
-			for l.ch != '\n' && l.ch != 0 {
-				tok.Literal += string(l.ch)
-				l.readChar()
-			}
-
-			return tok
 		} else if l.peekChar() == '*' {
 			l.readChar() //read the '*'
 			l.readChar() //read the next char after '*'
@@ -116,8 +222,22 @@ func (l *Lexer) NextToken() token.Token {
 		} else {
 			tok = newToken(token.SLASH, l.ch)
 		}
+	case '#':
+		tok.Type = token.SINGLE_COMMENT
+
+		//ignore the rest of the line
+		for l.ch != '\n' && l.ch != 0 {
+			tok.Literal += string(l.ch)
+			l.readChar()
+		}
+
+		return tok
 	case '*':
-		if l.peekChar() == '=' {
+		if l.match(token.POWER_EQ) {
+			tok = token.Token{Type: token.POWER_EQ, Literal: token.POWER_EQ}
+		} else if l.match(token.POWER) {
+			tok = token.Token{Type: token.POWER, Literal: token.POWER}
+		} else if l.peekChar() == '=' {
 			ch := l.ch
 			l.readChar()
 			tok = token.Token{Type: token.ASTERISK_EQ, Literal: string(ch) + string(l.ch)}
@@ -125,9 +245,17 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.ASTERISK, l.ch)
 		}
 	case '%':
-		tok = newToken(token.MODULO, l.ch)
+		if l.match(token.MODULO_EQ) {
+			tok = token.Token{Type: token.MODULO_EQ, Literal: token.MODULO_EQ}
+		} else {
+			tok = newToken(token.MODULO, l.ch)
+		}
 	case '<':
-		if l.peekChar() == '=' {
+		if l.match(token.SHIFT_LEFT_EQ) {
+			tok = token.Token{Type: token.SHIFT_LEFT_EQ, Literal: token.SHIFT_LEFT_EQ}
+		} else if l.match(token.SHIFT_LEFT) {
+			tok = token.Token{Type: token.SHIFT_LEFT, Literal: token.SHIFT_LEFT}
+		} else if l.peekChar() == '=' {
 			ch := l.ch
 			l.readChar()
 			tok = token.Token{Type: token.LT_EQ, Literal: string(ch) + string(l.ch)}
@@ -135,15 +263,37 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.LT, l.ch)
 		}
 	case '>':
-		if l.peekChar() == '=' {
+		if l.match(token.SHIFT_RIGHT_EQ) {
+			tok = token.Token{Type: token.SHIFT_RIGHT_EQ, Literal: token.SHIFT_RIGHT_EQ}
+		} else if l.match(token.SHIFT_RIGHT) {
+			tok = token.Token{Type: token.SHIFT_RIGHT, Literal: token.SHIFT_RIGHT}
+		} else if l.peekChar() == '=' {
 			ch := l.ch
 			l.readChar()
 			tok = token.Token{Type: token.GT_EQ, Literal: string(ch) + string(l.ch)}
 		} else {
 			tok = newToken(token.GT, l.ch)
 		}
+	case '&':
+		if l.match(token.AMPERSAND_EQ) {
+			tok = token.Token{Type: token.AMPERSAND_EQ, Literal: token.AMPERSAND_EQ}
+		} else {
+			tok = newToken(token.AMPERSAND, l.ch)
+		}
+	case '^':
+		if l.match(token.CARET_EQ) {
+			tok = token.Token{Type: token.CARET_EQ, Literal: token.CARET_EQ}
+		} else {
+			tok = newToken(token.CARET, l.ch)
+		}
+	case '~':
+		tok = newToken(token.TILDE, l.ch)
 	case '|':
-		tok = newToken(token.TERM, l.ch)
+		if l.match(token.PIPE) {
+			tok = token.Token{Type: token.PIPE, Literal: token.PIPE}
+		} else {
+			tok = newToken(token.TERM, l.ch)
+		}
 	case ':':
 		tok = newToken(token.COLON, l.ch)
 	case '(':
@@ -152,6 +302,16 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.RPAREN, l.ch)
 	case ',':
 		tok = newToken(token.COMMA, l.ch)
+	case '.':
+		if l.match(token.ELLIPSIS) {
+			tok = token.Token{Type: token.ELLIPSIS, Literal: token.ELLIPSIS}
+		} else if l.match(token.RANGE_EX) {
+			tok = token.Token{Type: token.RANGE_EX, Literal: token.RANGE_EX}
+		} else if l.match(token.RANGE) {
+			tok = token.Token{Type: token.RANGE, Literal: token.RANGE}
+		} else {
+			tok = newToken(token.DOT, l.ch)
+		}
 	case '{':
 		tok = newToken(token.LBRACE, l.ch)
 	case '}':
@@ -163,6 +323,9 @@ func (l *Lexer) NextToken() token.Token {
 	case '"':
 		tok.Type = token.STRING
 		tok.Literal = l.readString()
+	case '\'':
+		tok.Type = token.CHAR
+		tok.Literal = l.readCharLiteral()
 	case 0:
 		tok.Literal = ""
 		tok.Type = token.EOF
@@ -173,8 +336,7 @@ func (l *Lexer) NextToken() token.Token {
 
 			return tok
 		} else if isDigit(l.ch) {
-			tok.Type = token.INT
-			tok.Literal = l.readNumber()
+			tok.Type, tok.Literal = l.readNumber()
 			return tok
 		} else {
 			tok = newToken(token.ILLEGAL, l.ch)
@@ -202,13 +364,31 @@ func (l *Lexer) readIdentifier() string {
 	return l.input[position:l.position]
 }
 
-// reads continuous digits i.e. numbers
-func (l *Lexer) readNumber() string {
+// reads continuous digits i.e. numbers, or a decimal like 12.50d whose d
+// suffix marks it exact; a fraction without the suffix is ILLEGAL
+func (l *Lexer) readNumber() (token.TokenType, string) {
 	position := l.position
 	for isDigit(l.ch) {
 		l.readChar()
 	}
-	return l.input[position:l.position]
+
+	fraction := l.ch == '.' && isDigit(l.peekChar())
+	if fraction {
+		l.readChar()
+		for isDigit(l.ch) {
+			l.readChar()
+		}
+	}
+
+	if l.ch == 'd' && !isLetter(l.peekChar()) && !isDigit(l.peekChar()) {
+		l.readChar()
+		return token.DECIMAL, l.input[position:l.position]
+	}
+	if fraction {
+		return token.ILLEGAL, l.input[position:l.position]
+	}
+
+	return token.INT, l.input[position:l.position]
 }
 
 func (l *Lexer) readString() string {
@@ -223,6 +403,20 @@ func (l *Lexer) readString() string {
 	return l.input[position:l.position]
 }
 
+// reads everything up to the closing quote of a character literal; the parser
+// checks that it is a single character
+func (l *Lexer) readCharLiteral() string {
+	position := l.position + 1
+	for {
+		l.readChar()
+		if l.ch == '\'' || l.ch == 0 {
+			break
+		}
+	}
+
+	return l.input[position:l.position]
+}
+
 // Add chars here to allow them in var names or keywords
 func isLetter(ch rune) bool {
 	// fmt.Println('a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_' || 0x900 <= ch && ch <= 0x97F)
@@ -233,13 +427,32 @@ func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
-// eats up all the whitespace b/w the tokens (cuz they are just dividers)
+// eats up the whitespace b/w the tokens (cuz they are just dividers), stopping
+// at a line break that ends a statement
 func (l *Lexer) skipWhitespace() {
 	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
+		if l.ch == '\n' && l.atStatementEnd() {
+			return
+		}
 		l.readChar()
 	}
 }
 
+// reports whether the input continues with the ASCII operator op at the current
+// character, and if so consumes all of op but its last character, which the
+// caller reads past like any single character token
+func (l *Lexer) match(op string) bool {
+	if !strings.HasPrefix(l.input[l.position:], op) {
+		return false
+	}
+
+	for i := 1; i < len(op); i++ {
+		l.readChar()
+	}
+
+	return true
+}
+
 // peeks the next character but doesn't update the position as well as thre readPosition
 func (l *Lexer) peekChar() rune {
 	if l.readPosition >= len(l.input) {
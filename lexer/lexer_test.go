@@ -28,6 +28,7 @@ agar (5 < 10) {
 "acha thik hai"
 [1,2]|
 {"chota bheem": "motu patlu"}
+mana [a, ...rest] = arr|
 `
 
 	tests := []struct {
@@ -99,6 +100,7 @@ agar (5 < 10) {
 		{token.FALSE_LATIN, "asatya"},
 		{token.TERM, "|"},
 		{token.RBRACE, "}"},
+		{token.TERM, "\n"},
 		{token.INT, "10"},
 		{token.EQ, "=="},
 		{token.INT, "10"},
@@ -108,7 +110,9 @@ agar (5 < 10) {
 		{token.INT, "9"},
 		{token.TERM, "|"},
 		{token.STRING, "haanji"},
+		{token.TERM, "\n"},
 		{token.STRING, "acha thik hai"},
+		{token.TERM, "\n"},
 		{token.LBRACKET, "["},
 		{token.INT, "1"},
 		{token.COMMA, ","},
@@ -120,9 +124,180 @@ agar (5 < 10) {
 		{token.COLON, ":"},
 		{token.STRING, "motu patlu"},
 		{token.RBRACE, "}"},
+		{token.TERM, "\n"},
+		{token.LET_LATIN, "mana"},
+		{token.LBRACKET, "["},
+		{token.IDENT, "a"},
+		{token.COMMA, ","},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "rest"},
+		{token.RBRACKET, "]"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "arr"},
+		{token.TERM, "|"},
+		{token.EOF, ""},
 		{token.EOF, ""},
+	}
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q",
+				i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestTokenPositions(t *testing.T) {
+	input := `mana x = 5|
+  agar (x) {
+	"नमस्ते" + y|
+}`
+
+	tests := []struct {
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{"mana", 1, 1},
+		{"x", 1, 6},
+		{"=", 1, 8},
+		{"5", 1, 10},
+		{"|", 1, 11},
+		{"agar", 2, 3},
+		{"(", 2, 8},
+		{"x", 2, 9},
+		{")", 2, 10},
+		{"{", 2, 12},
+		{"नमस्ते", 3, 2},
+		{"+", 3, 11},
+		{"y", 3, 13},
+		{"|", 3, 14},
+		{"}", 4, 1},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Line != tt.expectedLine || tok.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - position of %q wrong. expected=%d:%d, got=%d:%d",
+				i, tok.Literal, tt.expectedLine, tt.expectedColumn, tok.Line, tok.Column)
+		}
+	}
+}
+
+func TestOperatorTokens(t *testing.T) {
+	input := `a ** b // c & d ^ ~e << f >> g
+x %= 1| x **= 2| x //= 3| x &= 4| x ^= 5| x <<= 6| x >>= 7|
+# yeh ek comment hai
+y / 2 /* aur yeh bhi */
+1..10 a..<b
+12.50d 7d 1.5 3dd
+p.naam
+_ => a == b = c
+'a' 'क' 'ab'
+x |> f| y`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.POWER, "**"},
+		{token.IDENT, "b"},
+		{token.FLOOR_DIV, "//"},
+		{token.IDENT, "c"},
+		{token.AMPERSAND, "&"},
+		{token.IDENT, "d"},
+		{token.CARET, "^"},
+		{token.TILDE, "~"},
+		{token.IDENT, "e"},
+		{token.SHIFT_LEFT, "<<"},
+		{token.IDENT, "f"},
+		{token.SHIFT_RIGHT, ">>"},
+		{token.IDENT, "g"},
+		{token.TERM, "\n"},
+		{token.IDENT, "x"},
+		{token.MODULO_EQ, "%="},
+		{token.INT, "1"},
+		{token.TERM, "|"},
+		{token.IDENT, "x"},
+		{token.POWER_EQ, "**="},
+		{token.INT, "2"},
+		{token.TERM, "|"},
+		{token.IDENT, "x"},
+		{token.FLOOR_DIV_EQ, "//="},
+		{token.INT, "3"},
+		{token.TERM, "|"},
+		{token.IDENT, "x"},
+		{token.AMPERSAND_EQ, "&="},
+		{token.INT, "4"},
+		{token.TERM, "|"},
+		{token.IDENT, "x"},
+		{token.CARET_EQ, "^="},
+		{token.INT, "5"},
+		{token.TERM, "|"},
+		{token.IDENT, "x"},
+		{token.SHIFT_LEFT_EQ, "<<="},
+		{token.INT, "6"},
+		{token.TERM, "|"},
+		{token.IDENT, "x"},
+		{token.SHIFT_RIGHT_EQ, ">>="},
+		{token.INT, "7"},
+		{token.TERM, "|"},
+		{token.SINGLE_COMMENT, "# yeh ek comment hai"},
+		{token.IDENT, "y"},
+		{token.SLASH, "/"},
+		{token.INT, "2"},
+		{token.MULTI_COMMENT, "/* aur yeh bhi */"},
+		{token.TERM, "\n"},
+		{token.INT, "1"},
+		{token.RANGE, ".."},
+		{token.INT, "10"},
+		{token.IDENT, "a"},
+		{token.RANGE_EX, "..<"},
+		{token.IDENT, "b"},
+		{token.TERM, "\n"},
+		{token.DECIMAL, "12.50d"},
+		{token.DECIMAL, "7d"},
+		{token.ILLEGAL, "1.5"},
+		{token.INT, "3"},
+		{token.IDENT, "dd"},
+		{token.TERM, "\n"},
+		{token.IDENT, "p"},
+		{token.DOT, "."},
+		{token.IDENT, "naam"},
+		{token.TERM, "\n"},
+		{token.IDENT, "_"},
+		{token.ARROW, "=>"},
+		{token.IDENT, "a"},
+		{token.EQ, "=="},
+		{token.IDENT, "b"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "c"},
+		{token.TERM, "\n"},
+		{token.CHAR, "a"},
+		{token.CHAR, "क"},
+		{token.CHAR, "ab"},
+		{token.TERM, "\n"},
+		{token.IDENT, "x"},
+		{token.PIPE, "|>"},
+		{token.IDENT, "f"},
+		{token.TERM, "|"},
+		{token.IDENT, "y"},
 		{token.EOF, ""},
 	}
+
 	l := New(input)
 
 	for i, tt := range tests {
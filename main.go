@@ -61,11 +61,18 @@ func main() {
 			repl.PrintParserErrors(out, p.Errors())
 			return
 		}
+		repl.PrintParserWarnings(out, p.Warnings())
 
 		stdout := []string{}
-		evaluator.Eval(program, env, &stdout)
+		evaluated := evaluator.Eval(program, env, &stdout)
+		env.CloseGenerators(&stdout)
 		for _, s := range stdout {
 			io.WriteString(out, s)
 		}
+
+		// an error nobody caught ends the script, so report it
+		if evaluated != nil && evaluated.Type() == object.ERROR_OBJ {
+			io.WriteString(out, evaluated.Inspect()+"\n")
+		}
 	}
 }
@@ -0,0 +1,90 @@
+package object
+
+import "strings"
+
+// Collation picks how strings are ordered
+type Collation int
+
+const (
+	// BYTE_COLLATION orders strings by code point, which is what < and > use
+	BYTE_COLLATION Collation = iota
+	// VARNAMALA_COLLATION orders Devanagari the way a Hindi dictionary does
+	VARNAMALA_COLLATION
+)
+
+var collations = map[string]Collation{
+	"byte":      BYTE_COLLATION,
+	"varnamala": VARNAMALA_COLLATION,
+	"वर्णमाला":  VARNAMALA_COLLATION,
+}
+
+// LookupCollation finds a collation by the name scripts refer to it with
+func LookupCollation(name string) (Collation, bool) {
+	c, ok := collations[name]
+	return c, ok
+}
+
+// Compare returns a negative number, zero or a positive number as a sorts
+// before, alongside or after b
+func (c Collation) Compare(a, b string) int {
+	if c == VARNAMALA_COLLATION {
+		if cmp := strings.Compare(varnamalaKey(a), varnamalaKey(b)); cmp != 0 {
+			return cmp
+		}
+	}
+
+	return strings.Compare(a, b)
+}
+
+// letters that sort as another on first pass: those written with a nukta go
+// with the letter beneath it (ज़मीन next to जमीन), and the vowels borrowed for
+// English words go with the nearest Hindi vowel (डॉक्टर next to डोर)
+var varnamalaFolds = map[rune]rune{
+	'\u0958': 'क', // क़
+	'\u0959': 'ख', // ख़
+	'\u095a': 'ग', // ग़
+	'\u095b': 'ज', // ज़
+	'\u095c': 'ड', // ड़
+	'\u095d': 'ढ', // ढ़
+	'\u095e': 'फ', // फ़
+	'\u095f': 'य', // य़
+	'\u0929': 'न', // ऩ
+	'\u0931': 'र', // ऱ
+	'\u0934': 'ळ', // ऴ
+	'\u090d': 'ए', // ऍ
+	'\u0911': 'ओ', // ऑ
+	'\u0945': 'े', // ॅ
+	'\u0949': 'ो', // ॉ
+}
+
+const (
+	nukta         = '\u093c'
+	chandrabindu  = '\u0901'
+	anusvara      = '\u0902'
+	zeroWidthJoin = '\u200d'
+	zeroWidthNon  = '\u200c'
+)
+
+// builds the primary sort key of a string. Code point order already lists the
+// vowels, then the consonants ka to ha, with a bare consonant before its
+// matras and conjuncts after them, so only the marks a dictionary disregards
+// on first pass need folding away; ties are broken on the original strings.
+func varnamalaKey(s string) string {
+	var out strings.Builder
+
+	for _, ch := range s {
+		switch {
+		case ch == nukta, ch == zeroWidthJoin, ch == zeroWidthNon:
+			continue
+		case ch == chandrabindu:
+			out.WriteRune(anusvara)
+		default:
+			if folded, ok := varnamalaFolds[ch]; ok {
+				ch = folded
+			}
+			out.WriteRune(ch)
+		}
+	}
+
+	return out.String()
+}
@@ -0,0 +1,279 @@
+package object
+
+import "math/big"
+
+// Equaler is implemented by objects that compare by value; everything else,
+// functions included, is only equal to itself
+type Equaler interface {
+	Equals(other Object) bool
+}
+
+// Comparer is implemented by objects with an ordering. Compare returns a
+// negative number, zero or a positive number as the receiver sorts before,
+// alongside or after other, and false when the two can't be ordered
+type Comparer interface {
+	Compare(other Object) (int, bool)
+}
+
+// Equals reports whether a and b hold the same value; values of different
+// types are never equal, except numbers of equal value
+func Equals(a, b Object) bool {
+	if e, ok := a.(Equaler); ok {
+		return e.Equals(b)
+	}
+
+	return a == b
+}
+
+// Compare orders a against b, reporting false when they can't be ordered
+func Compare(a, b Object) (int, bool) {
+	c, ok := a.(Comparer)
+	if !ok {
+		return 0, false
+	}
+
+	return c.Compare(b)
+}
+
+// CompareCollated is Compare with strings, including those inside arrays and
+// tuples, ordered by the given collation
+func CompareCollated(a, b Object, collation Collation) (int, bool) {
+	switch a := a.(type) {
+	case *String:
+		if b, ok := b.(*String); ok {
+			return collation.Compare(a.Value, b.Value), true
+		}
+	case *Array:
+		if b, ok := b.(*Array); ok {
+			return compareElements(a.Elements, b.Elements, collation)
+		}
+	case *Tuple:
+		if b, ok := b.(*Tuple); ok {
+			return compareElements(a.Elements, b.Elements, collation)
+		}
+	}
+
+	return Compare(a, b)
+}
+
+func (i *Integer) Equals(other Object) bool {
+	switch o := other.(type) {
+	case *Integer:
+		return i.Value == o.Value
+	case *BigInt, *Decimal:
+		return o.(Equaler).Equals(i)
+	default:
+		return false
+	}
+}
+
+// an Integer orders against a BigInt as well as another Integer
+func (i *Integer) Compare(other Object) (int, bool) {
+	switch o := other.(type) {
+	case *Integer:
+		switch {
+		case i.Value < o.Value:
+			return -1, true
+		case i.Value > o.Value:
+			return 1, true
+		default:
+			return 0, true
+		}
+	case *BigInt:
+		return big.NewInt(i.Value).Cmp(o.Value), true
+	case *Decimal:
+		cmp, ok := o.Compare(i)
+		return -cmp, ok
+	default:
+		return 0, false
+	}
+}
+
+func (bi *BigInt) Equals(other Object) bool {
+	switch o := other.(type) {
+	case *BigInt:
+		return bi.Value.Cmp(o.Value) == 0
+	case *Integer:
+		return bi.Value.IsInt64() && bi.Value.Int64() == o.Value
+	case *Decimal:
+		return o.Equals(bi)
+	default:
+		return false
+	}
+}
+
+func (bi *BigInt) Compare(other Object) (int, bool) {
+	switch o := other.(type) {
+	case *Integer:
+		return bi.Value.Cmp(big.NewInt(o.Value)), true
+	case *BigInt:
+		return bi.Value.Cmp(o.Value), true
+	case *Decimal:
+		cmp, ok := o.Compare(bi)
+		return -cmp, ok
+	default:
+		return 0, false
+	}
+}
+
+func (s *String) Equals(other Object) bool {
+	o, ok := other.(*String)
+	return ok && s.Value == o.Value
+}
+
+// strings order byte by byte, which for UTF-8 is the order of their code points
+func (s *String) Compare(other Object) (int, bool) {
+	o, ok := other.(*String)
+	if !ok {
+		return 0, false
+	}
+
+	return BYTE_COLLATION.Compare(s.Value, o.Value), true
+}
+
+func (c *Char) Equals(other Object) bool {
+	o, ok := other.(*Char)
+	return ok && c.Value == o.Value
+}
+
+// characters order by code point
+func (c *Char) Compare(other Object) (int, bool) {
+	o, ok := other.(*Char)
+	if !ok {
+		return 0, false
+	}
+
+	switch {
+	case c.Value < o.Value:
+		return -1, true
+	case c.Value > o.Value:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+func (b *Boolean) Equals(other Object) bool {
+	o, ok := other.(*Boolean)
+	return ok && b.Value == o.Value
+}
+
+func (n *Null) Equals(other Object) bool {
+	_, ok := other.(*Null)
+	return ok
+}
+
+func (ao *Array) Equals(other Object) bool {
+	o, ok := other.(*Array)
+	return ok && equalElements(ao.Elements, o.Elements)
+}
+
+func (t *Tuple) Equals(other Object) bool {
+	o, ok := other.(*Tuple)
+	return ok && equalElements(t.Elements, o.Elements)
+}
+
+func equalElements(a, b []Object) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i, el := range a {
+		if !Equals(el, b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// arrays order lexicographically, element by element, with a shorter array
+// sorting before a longer one it is a prefix of
+func (ao *Array) Compare(other Object) (int, bool) {
+	o, ok := other.(*Array)
+	if !ok {
+		return 0, false
+	}
+
+	return compareElements(ao.Elements, o.Elements, BYTE_COLLATION)
+}
+
+// tuples order like arrays
+func (t *Tuple) Compare(other Object) (int, bool) {
+	o, ok := other.(*Tuple)
+	if !ok {
+		return 0, false
+	}
+
+	return compareElements(t.Elements, o.Elements, BYTE_COLLATION)
+}
+
+func compareElements(a, b []Object, collation Collation) (int, bool) {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if Equals(a[i], b[i]) {
+			continue
+		}
+
+		return CompareCollated(a[i], b[i], collation)
+	}
+
+	return len(a) - len(b), true
+}
+
+func (h *Hash) Equals(other Object) bool {
+	o, ok := other.(*Hash)
+	if !ok || h.Len() != o.Len() {
+		return false
+	}
+
+	for _, pair := range h.pairs {
+		otherPair, ok := o.Get(pair.Key)
+		if !ok || !Equals(pair.Value, otherPair.Value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sets are equal when they hold the same elements, whatever order they were added in
+func (s *Set) Equals(other Object) bool {
+	o, ok := other.(*Set)
+	if !ok || s.Len() != o.Len() {
+		return false
+	}
+
+	for _, el := range s.Elements() {
+		if !o.Contains(el) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ranges are equal when they produce the same integers
+func (r *Range) Equals(other Object) bool {
+	o, ok := other.(*Range)
+	if !ok || r.Len() != o.Len() {
+		return false
+	}
+
+	return r.Len() == 0 || r.Start == o.Start && (r.Len() == 1 || r.Step == o.Step)
+}
+
+// records are equal when they share a dhancha and their fields are equal
+func (r *Record) Equals(other Object) bool {
+	o, ok := other.(*Record)
+	if !ok || r.RecordType != o.RecordType {
+		return false
+	}
+
+	for name, val := range r.Fields {
+		if !Equals(val, o.Fields[name]) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,294 @@
+package object
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"strings"
+)
+
+// Decimal is an exact base-10 number: Value scaled down by Scale decimal
+// places, so 12.50 is Value 1250 with Scale 2. Trailing zeros are kept, as a
+// bill shows ₹12.50 rather than ₹12.5
+type Decimal struct {
+	Value *big.Int
+	Scale int32
+}
+
+// how many decimal places a division that doesn't terminate is carried to
+// beyond the places of its operands
+const DivisionPlaces = 28
+
+// ParseDecimal reads a decimal such as "12.50", "-3" or "+0.125"
+func ParseDecimal(s string) (*Decimal, bool) {
+	digits := s
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		digits = digits[1:]
+	}
+
+	whole, fraction, hasPoint := strings.Cut(digits, ".")
+	if whole == "" || hasPoint && fraction == "" {
+		return nil, false
+	}
+	for _, ch := range whole + fraction {
+		if ch < '0' || ch > '9' {
+			return nil, false
+		}
+	}
+
+	value, ok := new(big.Int).SetString(whole+fraction, 10)
+	if !ok {
+		return nil, false
+	}
+	if strings.HasPrefix(s, "-") {
+		value.Neg(value)
+	}
+
+	return &Decimal{Value: value, Scale: int32(len(fraction))}, true
+}
+
+// ToDecimal widens an Integer or BigInt to a Decimal, and passes a Decimal through
+func ToDecimal(obj Object) (*Decimal, bool) {
+	switch obj := obj.(type) {
+	case *Decimal:
+		return obj, true
+	case *Integer:
+		return &Decimal{Value: big.NewInt(obj.Value)}, true
+	case *BigInt:
+		return &Decimal{Value: obj.Value}, true
+	default:
+		return nil, false
+	}
+}
+
+func (d *Decimal) Type() ObjectType { return DECIMAL_OBJ }
+
+// writes the whole part with Indian grouping: the last three digits, then
+// pairs for thousands, lakhs and crores, as in 1,23,45,678.90
+func (d *Decimal) Inspect() string {
+	digits := new(big.Int).Abs(d.Value).String()
+	if pad := int(d.Scale) + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+
+	whole, fraction := digits[:len(digits)-int(d.Scale)], digits[len(digits)-int(d.Scale):]
+
+	var out strings.Builder
+	if d.Value.Sign() < 0 {
+		out.WriteString("-")
+	}
+	out.WriteString(groupIndian(whole))
+	if d.Scale > 0 {
+		out.WriteString("." + fraction)
+	}
+
+	return out.String()
+}
+
+func groupIndian(whole string) string {
+	if len(whole) <= 3 {
+		return whole
+	}
+
+	head, tail := whole[:len(whole)-3], whole[len(whole)-3:]
+	groups := []string{tail}
+	for len(head) > 2 {
+		groups = append([]string{head[len(head)-2:]}, groups...)
+		head = head[:len(head)-2]
+	}
+
+	return head + "," + strings.Join(groups, ",")
+}
+
+// rescale returns d's digits at a scale at least as large as its own
+func (d *Decimal) rescale(scale int32) *big.Int {
+	return new(big.Int).Mul(d.Value, pow10(scale-d.Scale))
+}
+
+// align returns the digits of a and b at their common scale
+func align(a, b *Decimal) (*big.Int, *big.Int, int32) {
+	scale := a.Scale
+	if b.Scale > scale {
+		scale = b.Scale
+	}
+
+	return a.rescale(scale), b.rescale(scale), scale
+}
+
+func (d *Decimal) Add(other *Decimal) *Decimal {
+	a, b, scale := align(d, other)
+	return &Decimal{Value: a.Add(a, b), Scale: scale}
+}
+
+func (d *Decimal) Sub(other *Decimal) *Decimal {
+	a, b, scale := align(d, other)
+	return &Decimal{Value: a.Sub(a, b), Scale: scale}
+}
+
+func (d *Decimal) Mul(other *Decimal) *Decimal {
+	return &Decimal{Value: new(big.Int).Mul(d.Value, other.Value), Scale: d.Scale + other.Scale}
+}
+
+func (d *Decimal) Neg() *Decimal {
+	return &Decimal{Value: new(big.Int).Neg(d.Value), Scale: d.Scale}
+}
+
+// Quo divides exactly when the quotient terminates within DivisionPlaces
+// places past the operands' own, and otherwise rounds it there with mode.
+// The caller rules out a zero divisor
+func (d *Decimal) Quo(other *Decimal, mode RoundingMode) *Decimal {
+	scale := d.Scale
+	if other.Scale > scale {
+		scale = other.Scale
+	}
+
+	remainder := new(big.Int)
+	for places := scale; ; places++ {
+		// d / other at this many places is d.Value * 10^(places + other.Scale - d.Scale) / other.Value
+		numerator := new(big.Int).Mul(d.Value, pow10(places+other.Scale-d.Scale))
+		quotient, _ := new(big.Int).QuoRem(numerator, other.Value, remainder)
+
+		if remainder.Sign() == 0 {
+			return &Decimal{Value: quotient, Scale: places}
+		}
+		if places == scale+DivisionPlaces {
+			return &Decimal{Value: roundQuotient(quotient, remainder, other.Value, mode), Scale: places}
+		}
+	}
+}
+
+// Cmp returns -1, 0 or 1 as d is less than, equal to or greater than other
+func (d *Decimal) Cmp(other *Decimal) int {
+	a, b, _ := align(d, other)
+	return a.Cmp(b)
+}
+
+// Round returns d rounded to places decimal places; a d with fewer places
+// is padded with zeros
+func (d *Decimal) Round(places int32, mode RoundingMode) *Decimal {
+	if places >= d.Scale {
+		return &Decimal{Value: d.rescale(places), Scale: places}
+	}
+
+	divisor := pow10(d.Scale - places)
+	quotient, remainder := new(big.Int).QuoRem(d.Value, divisor, new(big.Int))
+
+	return &Decimal{Value: roundQuotient(quotient, remainder, divisor, mode), Scale: places}
+}
+
+// normalized drops trailing zeros so that equal decimals look alike
+func (d *Decimal) normalized() *Decimal {
+	value, scale := new(big.Int).Set(d.Value), d.Scale
+	ten, digit := big.NewInt(10), new(big.Int)
+
+	for scale > 0 {
+		quotient, _ := new(big.Int).QuoRem(value, ten, digit)
+		if digit.Sign() != 0 {
+			break
+		}
+		value, scale = quotient, scale-1
+	}
+
+	return &Decimal{Value: value, Scale: scale}
+}
+
+// a whole decimal equals, and so hashes like, the integer it holds
+func (d *Decimal) HashKey() HashKey {
+	n := d.normalized()
+	if n.Scale == 0 {
+		return NewInteger(n.Value).(Hashable).HashKey()
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(fmt.Sprintf("%s/%d", n.Value, n.Scale)))
+
+	return HashKey{Type: d.Type(), Value: h.Sum64()}
+}
+
+// decimals compare by value, so 12.5 equals 12.50 and 12.0 equals 12
+func (d *Decimal) Equals(other Object) bool {
+	o, ok := ToDecimal(other)
+	return ok && d.Cmp(o) == 0
+}
+
+func (d *Decimal) Compare(other Object) (int, bool) {
+	o, ok := ToDecimal(other)
+	if !ok {
+		return 0, false
+	}
+
+	return d.Cmp(o), true
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// RoundingMode picks which way a value halfway or partway between two
+// results goes
+type RoundingMode int
+
+const (
+	ROUND_HALF_UP   RoundingMode = iota // halves away from zero, as taught in school
+	ROUND_HALF_EVEN                     // halves to the even neighbour, the banker's rounding
+	ROUND_HALF_DOWN                     // halves towards zero
+	ROUND_UP                            // away from zero
+	ROUND_DOWN                          // towards zero, dropping the extra digits
+	ROUND_CEILING                       // towards positive infinity
+	ROUND_FLOOR                         // towards negative infinity
+)
+
+var roundingModes = map[string]RoundingMode{
+	"half_up":   ROUND_HALF_UP,
+	"half_even": ROUND_HALF_EVEN,
+	"half_down": ROUND_HALF_DOWN,
+	"up":        ROUND_UP,
+	"down":      ROUND_DOWN,
+	"ceiling":   ROUND_CEILING,
+	"floor":     ROUND_FLOOR,
+}
+
+// LookupRoundingMode finds a rounding mode by the name scripts refer to it with
+func LookupRoundingMode(name string) (RoundingMode, bool) {
+	mode, ok := roundingModes[name]
+	return mode, ok
+}
+
+// roundQuotient rounds the truncated quotient of a division, given what
+// remained of it and the divisor, to one of its two integer neighbours
+func roundQuotient(quotient, remainder, divisor *big.Int, mode RoundingMode) *big.Int {
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+
+	// the exact result lies past quotient, away from zero, in this direction
+	sign := remainder.Sign() * divisor.Sign()
+
+	twice := new(big.Int).Abs(remainder)
+	twice.Lsh(twice, 1)
+	half := twice.Cmp(new(big.Int).Abs(divisor)) // <0 below half, 0 at half, >0 above
+
+	var away bool
+	switch mode {
+	case ROUND_HALF_UP:
+		away = half >= 0
+	case ROUND_HALF_EVEN:
+		away = half > 0 || half == 0 && quotient.Bit(0) == 1
+	case ROUND_HALF_DOWN:
+		away = half > 0
+	case ROUND_UP:
+		away = true
+	case ROUND_DOWN:
+		away = false
+	case ROUND_CEILING:
+		away = sign > 0
+	case ROUND_FLOOR:
+		away = sign < 0
+	}
+
+	if !away {
+		return quotient
+	}
+
+	return new(big.Int).Add(quotient, big.NewInt(int64(sign)))
+}
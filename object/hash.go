@@ -0,0 +1,245 @@
+package object
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// HashKeyOf hashes any value usable as a hash key or set element: the
+// Hashable scalars, plus arrays, tuples and records whose contents are all
+// hashable, which hash by structure so equal ones share a key
+func HashKeyOf(obj Object) (HashKey, bool) {
+	switch obj := obj.(type) {
+	case Hashable:
+		return obj.HashKey(), true
+	case *Array:
+		return hashAll(obj.Type(), obj.Elements)
+	case *Tuple:
+		return hashAll(obj.Type(), obj.Elements)
+	case *Record:
+		fields := make([]Object, 0, len(obj.RecordType.Fields))
+		for _, name := range obj.RecordType.Fields {
+			fields = append(fields, obj.Fields[name])
+		}
+		return hashAll(obj.Type(), fields)
+	default:
+		return HashKey{}, false
+	}
+}
+
+// freezes the records in a value just stored as a key, so that assigning
+// their fields can't change its hash and strand it
+func freeze(obj Object) {
+	switch obj := obj.(type) {
+	case *Array:
+		for _, el := range obj.Elements {
+			freeze(el)
+		}
+	case *Tuple:
+		for _, el := range obj.Elements {
+			freeze(el)
+		}
+	case *Record:
+		obj.Frozen = true
+		for _, field := range obj.Fields {
+			freeze(field)
+		}
+	}
+}
+
+// combines the keys of objs, in order
+func hashAll(t ObjectType, objs []Object) (HashKey, bool) {
+	h := fnv.New64a()
+
+	buf := make([]byte, 8)
+	for _, obj := range objs {
+		key, ok := HashKeyOf(obj)
+		if !ok {
+			return HashKey{}, false
+		}
+
+		h.Write([]byte(key.Type))
+		binary.LittleEndian.PutUint64(buf, key.Value)
+		h.Write(buf)
+	}
+
+	return HashKey{Type: t, Value: h.Sum64()}, true
+}
+
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// Hash maps keys to values and remembers the order keys were first set in,
+// which is the order it iterates and prints in. Keys sharing a HashKey go in
+// the same bucket and are told apart by Equals, so a collision never mixes
+// up two keys
+type Hash struct {
+	pairs   []HashPair
+	buckets map[HashKey][]int // positions in pairs of the keys with each HashKey
+}
+
+func NewHash() *Hash {
+	return &Hash{buckets: map[HashKey][]int{}}
+}
+
+// find returns the position of key in h.pairs, or -1
+func (h *Hash) find(hashed HashKey, key Object) int {
+	for _, i := range h.buckets[hashed] {
+		if Equals(h.pairs[i].Key, key) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Get looks up the pair for key, reporting false if key is missing or unhashable
+func (h *Hash) Get(key Object) (HashPair, bool) {
+	hashed, ok := HashKeyOf(key)
+	if !ok {
+		return HashPair{}, false
+	}
+
+	if i := h.find(hashed, key); i >= 0 {
+		return h.pairs[i], true
+	}
+
+	return HashPair{}, false
+}
+
+// Set adds or replaces the value for key; replacing a key keeps its original
+// position. A record in a new key is frozen. It reports false if key is
+// unhashable
+func (h *Hash) Set(key, value Object) bool {
+	hashed, ok := HashKeyOf(key)
+	if !ok {
+		return false
+	}
+
+	if i := h.find(hashed, key); i >= 0 {
+		h.pairs[i].Value = value
+		return true
+	}
+
+	freeze(key)
+	h.buckets[hashed] = append(h.buckets[hashed], len(h.pairs))
+	h.pairs = append(h.pairs, HashPair{Key: key, Value: value})
+
+	return true
+}
+
+func (h *Hash) Len() int { return len(h.pairs) }
+
+// OrderedPairs returns the pairs in insertion order
+func (h *Hash) OrderedPairs() []HashPair {
+	pairs := make([]HashPair, len(h.pairs))
+	copy(pairs, h.pairs)
+
+	return pairs
+}
+
+func (h *Hash) Type() ObjectType { return HASH_OBJ }
+func (h *Hash) Inspect() string {
+	var out bytes.Buffer
+	pairs := []string{}
+	for _, pair := range h.pairs {
+		pairs = append(pairs, fmt.Sprintf("%s: %s",
+			pair.Key.Inspect(), pair.Value.Inspect()))
+	}
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+	return out.String()
+}
+
+// Set is an unordered collection of distinct hashable values, though like a
+// Hash it iterates and prints in the order elements were added
+type Set struct {
+	items *Hash
+}
+
+func NewSet() *Set {
+	return &Set{items: NewHash()}
+}
+
+// Add puts el in the set, reporting false if el is unhashable
+func (s *Set) Add(el Object) bool {
+	return s.items.Set(el, el)
+}
+
+func (s *Set) Contains(el Object) bool {
+	_, ok := s.items.Get(el)
+	return ok
+}
+
+func (s *Set) Len() int { return s.items.Len() }
+
+// Elements returns the elements in the order they were added
+func (s *Set) Elements() []Object {
+	elements := make([]Object, 0, s.Len())
+	for _, pair := range s.items.pairs {
+		elements = append(elements, pair.Key)
+	}
+
+	return elements
+}
+
+// Union returns the elements in either set
+func (s *Set) Union(other *Set) *Set {
+	result := NewSet()
+	for _, el := range s.Elements() {
+		result.Add(el)
+	}
+	for _, el := range other.Elements() {
+		result.Add(el)
+	}
+
+	return result
+}
+
+// Intersection returns the elements in both sets
+func (s *Set) Intersection(other *Set) *Set {
+	return s.filter(func(el Object) bool { return other.Contains(el) })
+}
+
+// Difference returns the elements of s that aren't in other
+func (s *Set) Difference(other *Set) *Set {
+	return s.filter(func(el Object) bool { return !other.Contains(el) })
+}
+
+// SymmetricDifference returns the elements in exactly one of the sets
+func (s *Set) SymmetricDifference(other *Set) *Set {
+	return s.Difference(other).Union(other.Difference(s))
+}
+
+func (s *Set) filter(keep func(Object) bool) *Set {
+	result := NewSet()
+	for _, el := range s.Elements() {
+		if keep(el) {
+			result.Add(el)
+		}
+	}
+
+	return result
+}
+
+func (s *Set) Type() ObjectType { return SET_OBJ }
+
+// an empty set prints as samuchchay(), since {} is an empty hash
+func (s *Set) Inspect() string {
+	if s.Len() == 0 {
+		return "samuchchay()"
+	}
+
+	elements := []string{}
+	for _, el := range s.Elements() {
+		elements = append(elements, el.Inspect())
+	}
+
+	return "{" + strings.Join(elements, ", ") + "}"
+}
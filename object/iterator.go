@@ -0,0 +1,304 @@
+package object
+
+import (
+	"container/list"
+	"runtime"
+	"sync"
+	"unicode/utf8"
+)
+
+// Iterator hands out the elements of a sequence one at a time. Next reports
+// false once none are left; an *Error it returns ends the sequence early.
+// Anything printed while producing an element goes to stdout
+type Iterator interface {
+	Next(stdout *[]string) (Object, bool)
+}
+
+// Iterable is implemented by what a har loop can walk over: arrays, tuples,
+// strings (by character), ranges, hashes (by key), sets, ganana (by variant)
+// and generators, which are their own iterator
+type Iterable interface {
+	Object
+	Iter() Iterator
+}
+
+// IteratorFunc makes an Iterator of a function returning each element in turn
+type IteratorFunc func() (Object, bool)
+
+func (f IteratorFunc) Next(stdout *[]string) (Object, bool) { return f() }
+
+// walks elements, a snapshot taken when iteration starts
+func iterateElements(elements []Object) Iterator {
+	i := 0
+	return IteratorFunc(func() (Object, bool) {
+		if i >= len(elements) {
+			return nil, false
+		}
+		i++
+		return elements[i-1], true
+	})
+}
+
+func (ao *Array) Iter() Iterator { return iterateElements(ao.Elements) }
+func (t *Tuple) Iter() Iterator  { return iterateElements(t.Elements) }
+func (s *Set) Iter() Iterator    { return iterateElements(s.Elements()) }
+
+func (s *String) Iter() Iterator {
+	rest := s.Value
+	return IteratorFunc(func() (Object, bool) {
+		if rest == "" {
+			return nil, false
+		}
+		ch, size := utf8.DecodeRuneInString(rest)
+		rest = rest[size:]
+		return &Char{Value: ch}, true
+	})
+}
+
+func (r *Range) Iter() Iterator {
+	i := int64(0)
+	return IteratorFunc(func() (Object, bool) {
+		if i >= r.Len() {
+			return nil, false
+		}
+		i++
+		return &Integer{Value: r.At(i - 1)}, true
+	})
+}
+
+func (h *Hash) Iter() Iterator {
+	keys := make([]Object, 0, h.Len())
+	for _, pair := range h.pairs {
+		keys = append(keys, pair.Key)
+	}
+
+	return iterateElements(keys)
+}
+
+func (e *Enum) Iter() Iterator {
+	variants := make([]Object, 0, len(e.Variants))
+	for _, variant := range e.Variants {
+		variants = append(variants, variant)
+	}
+
+	return iterateElements(variants)
+}
+
+// one value a generator's body hands back: a yielded value, or with done
+// set, what the body returned
+type generatorStep struct {
+	value Object
+	done  bool
+}
+
+// Generator is a suspended run of a generator function's body. The body runs
+// on a goroutine of its own, but only ever while Next or Close waits on it, so
+// it never runs alongside the rest of the program. A generator left midway
+// keeps its goroutine until it is closed, which a tracked one also is once
+// nothing refers to it
+type Generator struct {
+	Name string // empty for anonymous functions
+
+	run *generatorRun
+}
+
+// generatorRun is what a generator's goroutine shares with the Generator
+// resuming it. The goroutine holds only this, so a Generator nothing refers to
+// any more can be collected, and its run closed
+type generatorRun struct {
+	body    func(yield func(Object) bool, stdout *[]string) Object
+	resume  chan bool
+	steps   chan generatorStep
+	output  []string // printed by the body since it was last resumed
+	started bool
+	running bool
+	done    bool
+
+	tracker *generators   // set once the run is tracked
+	live    *list.Element // its place in tracker.live while started and unfinished
+}
+
+// NewGenerator returns a generator that runs body lazily: the first Next
+// starts it and each Next after resumes it, returning the value of the next
+// yield. yield reports false once the generator is closed, and body should
+// then return as soon as it can. body prints to stdout, and what it prints
+// reaches whoever resumed it
+func NewGenerator(name string, body func(yield func(Object) bool, stdout *[]string) Object) *Generator {
+	return &Generator{
+		Name: name,
+		run: &generatorRun{
+			body:   body,
+			resume: make(chan bool),
+			steps:  make(chan generatorStep),
+		},
+	}
+}
+
+func (g *Generator) Next(stdout *[]string) (Object, bool) {
+	defer runtime.KeepAlive(g)
+	return g.run.next(stdout)
+}
+
+// Close ends a generator that won't be resumed again. Every yield in its body
+// then reports false, and Close waits for the body to return, so its
+// goroutine ends too
+func (g *Generator) Close(stdout *[]string) {
+	defer runtime.KeepAlive(g)
+	g.run.close(stdout)
+}
+
+func (r *generatorRun) next(stdout *[]string) (Object, bool) {
+	if r.done {
+		return nil, false
+	}
+	if r.running {
+		return &Error{Message: "generator already running", Kind: RUNTIME_ERROR}, true
+	}
+
+	if !r.started {
+		r.started = true
+		if r.tracker != nil {
+			r.live = r.tracker.live.PushBack(r)
+		}
+		go r.loop()
+	}
+
+	r.running = true
+	r.resume <- true
+	step := r.wait(stdout)
+	if step.done {
+		r.finish()
+		if err, ok := step.value.(*Error); ok {
+			return err, true
+		}
+		return nil, false
+	}
+
+	return step.value, true
+}
+
+func (r *generatorRun) close(stdout *[]string) {
+	if r.running || r.done {
+		return
+	}
+
+	if r.started {
+		r.running = true
+		close(r.resume)
+		r.wait(stdout)
+	}
+	r.finish()
+}
+
+// marks the run done, so it is no longer tracked
+func (r *generatorRun) finish() {
+	r.done = true
+	if r.live != nil {
+		r.tracker.live.Remove(r.live)
+		r.live = nil
+	}
+}
+
+// waits for the body, just resumed, to hand back its next step, passing on
+// what it printed meanwhile
+func (r *generatorRun) wait(stdout *[]string) generatorStep {
+	step := <-r.steps
+	r.running = false
+
+	*stdout = append(*stdout, r.output...)
+	r.output = nil
+
+	return step
+}
+
+func (r *generatorRun) loop() {
+	<-r.resume
+
+	closed := false
+	result := r.body(func(value Object) bool {
+		if closed {
+			return false
+		}
+
+		r.steps <- generatorStep{value: value}
+		_, ok := <-r.resume
+		closed = !ok
+		return ok
+	}, &r.output)
+
+	r.steps <- generatorStep{value: result, done: true}
+}
+
+func (g *Generator) Iter() Iterator { return g }
+
+func (g *Generator) Type() ObjectType { return GENERATOR_OBJ }
+func (g *Generator) Inspect() string {
+	if g.Name == "" {
+		return "generator"
+	}
+	return "generator " + g.Name
+}
+
+// generators tracks the runs of the generators made in one program: those
+// started and not yet finished, in the order they started, and those whose
+// Generator was collected since they were last closed
+type generators struct {
+	live    *list.List
+	collect int // how many may be live before looking for collected ones
+
+	mu        sync.Mutex // guards abandoned, which the garbage collector adds to
+	abandoned []*generatorRun
+}
+
+func newGenerators() *generators {
+	return &generators{live: list.New(), collect: minCollect}
+}
+
+// the fewest live runs worth a garbage collection to find abandoned ones
+const minCollect = 1024
+
+// track follows g's run, and queues it to be closed once g is collected.
+// Each run keeps a goroutine, which the garbage collector doesn't count for
+// much, so once the live runs double it is asked to look for collected ones
+func (t *generators) track(g *Generator) {
+	if t.live.Len() >= t.collect {
+		runtime.GC()
+		t.collect = 2 * t.live.Len()
+	}
+
+	run := g.run
+	run.tracker = t
+
+	runtime.SetFinalizer(g, func(*Generator) {
+		t.mu.Lock()
+		t.abandoned = append(t.abandoned, run)
+		t.mu.Unlock()
+	})
+}
+
+// closeAbandoned closes the runs of the generators collected so far
+func (t *generators) closeAbandoned(stdout *[]string) {
+	t.mu.Lock()
+	abandoned := t.abandoned
+	t.abandoned = nil
+	t.mu.Unlock()
+
+	for _, run := range abandoned {
+		run.close(stdout)
+	}
+	if len(abandoned) > 0 {
+		t.collect = minCollect
+		if t.live.Len() > minCollect/2 {
+			t.collect = 2 * t.live.Len()
+		}
+	}
+}
+
+// closeAll closes every run started and not yet finished, oldest first,
+// including any started meanwhile
+func (t *generators) closeAll(stdout *[]string) {
+	for e := t.live.Front(); e != nil; e = t.live.Front() {
+		e.Value.(*generatorRun).close(stdout)
+	}
+	t.closeAbandoned(stdout)
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"fmt"
 	"hash/fnv"
+	"math"
+	"math/big"
 	"strings"
 
 	"github.com/Suryansh-23/amrit/ast"
@@ -13,7 +15,10 @@ type ObjectType string
 
 const (
 	INTEGER_OBJ      = "INTEGER"
+	BIGINT_OBJ       = "BIGINT"
+	DECIMAL_OBJ      = "DECIMAL"
 	STRING_OBJ       = "STRING"
+	CHAR_OBJ         = "CHAR"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	NULL_OBJ         = "NULL"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
@@ -22,7 +27,35 @@ const (
 	BUILTIN_OBJ      = "BUILTIN"
 	ARRAY_OBJ        = "ARRAY"
 	SLICE_OBJ        = "SLICE"
+	RANGE_OBJ        = "RANGE"
 	HASH_OBJ         = "HASH"
+	EXCEPTION_OBJ    = "EXCEPTION"
+	RECORD_TYPE_OBJ  = "RECORD_TYPE"
+	RECORD_OBJ       = "RECORD"
+	BOUND_METHOD_OBJ = "BOUND_METHOD"
+	CLASS_OBJ        = "CLASS"
+	INSTANCE_OBJ     = "INSTANCE"
+	SUPER_OBJ        = "SUPER"
+	ENUM_OBJ         = "ENUM"
+	ENUM_VALUE_OBJ   = "ENUM_VALUE"
+	SET_OBJ          = "SET"
+	TUPLE_OBJ        = "TUPLE"
+	GENERATOR_OBJ    = "GENERATOR"
+)
+
+// kinds of runtime errors, exposed to scripts through a caught Exception
+const (
+	RUNTIME_ERROR  = "RuntimeError"
+	TYPE_ERROR     = "TypeError"
+	NAME_ERROR     = "NameError"
+	VALUE_ERROR    = "ValueError"
+	ARGUMENT_ERROR = "ArgumentError"
+	ZERO_DIV_ERROR = "ZeroDivisionError"
+	USER_ERROR     = "Error" // raised with phenko
+
+	// unwinds the body of a closed generator from the upaj it was left at;
+	// pakdo never catches it, though aakhir blocks still run
+	GENERATOR_EXIT = "GeneratorExit"
 )
 
 type Object interface {
@@ -37,6 +70,24 @@ type Integer struct {
 func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
 func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
 
+// BigInt holds integers too large for an Integer; arithmetic promotes to it on
+// overflow and falls back to an Integer once the result fits again
+type BigInt struct {
+	Value *big.Int
+}
+
+// NewInteger returns an Integer when value fits in 64 bits and a BigInt otherwise
+func NewInteger(value *big.Int) Object {
+	if value.IsInt64() {
+		return &Integer{Value: value.Int64()}
+	}
+
+	return &BigInt{Value: value}
+}
+
+func (bi *BigInt) Type() ObjectType { return BIGINT_OBJ }
+func (bi *BigInt) Inspect() string  { return bi.Value.String() }
+
 type String struct {
 	Value string
 }
@@ -44,6 +95,15 @@ type String struct {
 func (s *String) Type() ObjectType { return STRING_OBJ }
 func (s *String) Inspect() string  { return s.Value }
 
+// Char is a single Unicode code point, which is what indexing or iterating
+// over a string gives; a matra or halant is a Char of its own
+type Char struct {
+	Value rune
+}
+
+func (c *Char) Type() ObjectType { return CHAR_OBJ }
+func (c *Char) Inspect() string  { return string(c.Value) }
+
 type Boolean struct {
 	Value bool
 }
@@ -69,16 +129,51 @@ type ReturnValue struct {
 func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
 func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
 
+// Error aborts evaluation until it is caught by a koshish/pakdo block
 type Error struct {
 	Message string
+	Kind    string
+	Line    int // position of the node that raised it, 0 if unknown
+	Column  int
+	Value   Object // the value passed to phenko, if any
 }
 
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
-func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
+func (e *Error) Inspect() string {
+	var out bytes.Buffer
+
+	out.WriteString("ERROR: ")
+	if e.Kind != "" {
+		out.WriteString(e.Kind + ": ")
+	}
+	out.WriteString(e.Message)
+	if e.Line > 0 {
+		out.WriteString(fmt.Sprintf(" (line %d, column %d)", e.Line, e.Column))
+	}
+
+	return out.String()
+}
+
+// Exception is the first-class value a pakdo block receives for a caught Error.
+// Scripts read its fields by indexing it with "sandesh" (message), "prakar" (kind),
+// "pankti" (line), "stambh" (column) and "mulya" (the thrown value).
+type Exception struct {
+	Message string
+	Kind    string
+	Line    int
+	Column  int
+	Value   Object
+}
+
+func (e *Exception) Type() ObjectType { return EXCEPTION_OBJ }
+func (e *Exception) Inspect() string  { return e.Kind + ": " + e.Message }
 
 type Environment struct {
 	store map[string]Object
 	outer *Environment
+	yield func(Object) bool // set on the environment a generator's body runs in
+
+	generators *generators // those made in a program run here, on the outermost environment
 }
 
 func NewEnvironment() *Environment {
@@ -106,10 +201,62 @@ func (e *Environment) Set(name string, val Object) Object {
 	return val
 }
 
+// SetYield makes e the environment of a generator's body, whose upaj hands
+// values to yield
+func (e *Environment) SetYield(yield func(Object) bool) {
+	e.yield = yield
+}
+
+// Track notes a generator made while running a program in e, so that
+// CloseGenerators can finish it off when nothing can resume it any more. It
+// also closes those collected since, printing to stdout
+func (e *Environment) Track(g *Generator, stdout *[]string) {
+	root := e.root()
+	if root.generators == nil {
+		root.generators = newGenerators()
+	}
+
+	root.generators.closeAbandoned(stdout)
+	root.generators.track(g)
+}
+
+// CloseAbandonedGenerators closes the unfinished generators made in the
+// program run in e that nothing refers to any more
+func (e *Environment) CloseAbandonedGenerators(stdout *[]string) {
+	if root := e.root(); root.generators != nil {
+		root.generators.closeAbandoned(stdout)
+	}
+}
+
+// CloseGenerators closes every generator made in the program run in e and
+// left unfinished, once that program is done with them
+func (e *Environment) CloseGenerators(stdout *[]string) {
+	if root := e.root(); root.generators != nil {
+		root.generators.closeAll(stdout)
+	}
+}
+
+func (e *Environment) root() *Environment {
+	for e.outer != nil {
+		e = e.outer
+	}
+	return e
+}
+
+// Yield returns the yield function of the generator body e is inside, or nil
+func (e *Environment) Yield() func(Object) bool {
+	if e.yield == nil && e.outer != nil {
+		return e.outer.Yield()
+	}
+	return e.yield
+}
+
 type Function struct {
-	Parameters []*ast.Identifier
+	Name       string // empty for anonymous functions
+	Parameters []ast.Expression
 	Body       *ast.BlockStatement
 	Env        *Environment
+	Generator  bool // calls return a Generator running Body rather than running it
 }
 
 func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
@@ -121,6 +268,14 @@ func (f *Function) Inspect() string {
 	}
 
 	out.WriteString("karya")
+	if f.Name != "" {
+		// named functions are identified by their signature alone
+		out.WriteString(" " + f.Name)
+		out.WriteString("(")
+		out.WriteString(strings.Join(params, ", "))
+		out.WriteString(")")
+		return out.String()
+	}
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(") { ")
@@ -159,6 +314,25 @@ func (ao *Array) Inspect() string {
 	return out.String()
 }
 
+// Tuple is a fixed sequence of values; unlike an Array it has no methods to
+// change it, so a tuple of hashable values can be a hash key
+type Tuple struct {
+	Elements []Object
+}
+
+func (t *Tuple) Type() ObjectType { return TUPLE_OBJ }
+func (t *Tuple) Inspect() string {
+	elements := []string{}
+	for _, e := range t.Elements {
+		elements = append(elements, e.Inspect())
+	}
+
+	if len(elements) == 1 {
+		return "(" + elements[0] + ",)"
+	}
+	return "(" + strings.Join(elements, ", ") + ")"
+}
+
 type Slice struct {
 	Left  Object
 	Right Object
@@ -177,6 +351,279 @@ func (s *Slice) Inspect() string {
 	return out.String()
 }
 
+// Range is a lazy sequence of Length integers from Start, Step apart. It keeps
+// a count rather than an end, as the integer after its last one may not fit
+// in an int64
+type Range struct {
+	Start  int64
+	Step   int64
+	Length int64
+}
+
+// NewRange builds the range start, start+step, ... that stops before stop,
+// reporting false if it would hold more integers than an int64 can count
+func NewRange(start, stop, step int64) (*Range, bool) {
+	// distances between int64s can exceed an int64, but never a uint64
+	var dist, stride uint64
+	switch {
+	case step > 0 && start < stop:
+		dist, stride = uint64(stop)-uint64(start), uint64(step)
+	case step < 0 && start > stop:
+		dist, stride = uint64(start)-uint64(stop), -uint64(step)
+	default:
+		return &Range{Start: start, Step: step}, true
+	}
+
+	n := (dist-1)/stride + 1
+	if n > math.MaxInt64 {
+		return nil, false
+	}
+
+	return &Range{Start: start, Step: step, Length: int64(n)}, true
+}
+
+func (r *Range) Len() int64 { return r.Length }
+
+// At returns the integer at position i, which must be below Len. Start + i*Step
+// may overflow on the way, but wraps around to the right integer
+func (r *Range) At(i int64) int64 { return r.Start + i*r.Step }
+
+// Contains reports whether n is one of r's integers
+func (r *Range) Contains(n int64) bool {
+	var dist, stride uint64
+	switch {
+	case r.Step > 0 && n >= r.Start:
+		dist, stride = uint64(n)-uint64(r.Start), uint64(r.Step)
+	case r.Step < 0 && n <= r.Start:
+		dist, stride = uint64(r.Start)-uint64(n), -uint64(r.Step)
+	default:
+		return false
+	}
+
+	return dist%stride == 0 && dist/stride < uint64(r.Length)
+}
+
+func (r *Range) Type() ObjectType { return RANGE_OBJ }
+func (r *Range) Inspect() string {
+	n := r.Len()
+	switch {
+	case n == 0:
+		return fmt.Sprintf("%d..<%d", r.Start, r.Start)
+	case r.Step == 1:
+		return fmt.Sprintf("%d..%d", r.Start, r.At(n-1))
+	case r.Step > 0:
+		return fmt.Sprintf("(%d..%d)[::%d]", r.Start, r.At(n-1), r.Step)
+	default:
+		return fmt.Sprintf("(%d..%d)[::%d]", r.At(n-1), r.Start, r.Step)
+	}
+}
+
+// RecordType is what a dhancha declaration binds; calling it builds a Record
+type RecordType struct {
+	Name    string
+	Fields  []string
+	Methods map[string]*Function
+}
+
+func (rt *RecordType) Type() ObjectType { return RECORD_TYPE_OBJ }
+func (rt *RecordType) Inspect() string {
+	return "dhancha " + rt.Name + " { " + strings.Join(rt.Fields, ", ") + " }"
+}
+
+// Record is a value of a RecordType, holding exactly its fields
+type Record struct {
+	RecordType *RecordType
+	Fields     map[string]Object
+	Frozen     bool // once it is a hash key or set element, its fields can't be assigned
+}
+
+func (r *Record) Get(field string) (Object, bool) {
+	val, ok := r.Fields[field]
+	return val, ok
+}
+
+// Set replaces a field's value, reporting false if the record has no such field
+func (r *Record) Set(field string, val Object) bool {
+	if _, ok := r.Fields[field]; !ok {
+		return false
+	}
+
+	r.Fields[field] = val
+	return true
+}
+
+func (r *Record) Type() ObjectType { return RECORD_OBJ }
+
+// lists the fields in the order they were declared
+func (r *Record) Inspect() string {
+	var out bytes.Buffer
+
+	fields := []string{}
+	for _, name := range r.RecordType.Fields {
+		fields = append(fields, name+": "+r.Fields[name].Inspect())
+	}
+
+	out.WriteString(r.RecordType.Name)
+	out.WriteString("{")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// BoundMethod is a method looked up on a value, remembering that value so
+// calling the method passes it along
+type BoundMethod struct {
+	Receiver Object
+	Name     string
+	Method   Object
+}
+
+func (bm *BoundMethod) Type() ObjectType { return BOUND_METHOD_OBJ }
+func (bm *BoundMethod) Inspect() string {
+	switch receiver := bm.Receiver.(type) {
+	case *Instance:
+		return fmt.Sprintf("method %s of %s", bm.Name, receiver.Class.Name)
+	case *Record:
+		return fmt.Sprintf("method %s of %s", bm.Name, receiver.RecordType.Name)
+	}
+	return fmt.Sprintf("method %s of %s", bm.Name, bm.Receiver.Type())
+}
+
+// Class is what a varg declaration binds; calling it builds an Instance and
+// runs its naya constructor
+type Class struct {
+	Name    string
+	Parent  *Class
+	Methods map[string]*Function
+}
+
+// FindMethod looks a method up on the class and then up its parents,
+// returning the class that defines it
+func (c *Class) FindMethod(name string) (*Function, *Class, bool) {
+	for class := c; class != nil; class = class.Parent {
+		if method, ok := class.Methods[name]; ok {
+			return method, class, true
+		}
+	}
+
+	return nil, nil, false
+}
+
+func (c *Class) Type() ObjectType { return CLASS_OBJ }
+func (c *Class) Inspect() string {
+	if c.Parent != nil {
+		return "varg " + c.Name + "(" + c.Parent.Name + ")"
+	}
+	return "varg " + c.Name
+}
+
+// Instance is an object of a Class. Its fields are whatever its methods
+// assign through yeh, kept in the order they were first set
+type Instance struct {
+	Class  *Class
+	Fields map[string]Object
+	order  []string
+}
+
+func NewInstance(class *Class) *Instance {
+	return &Instance{Class: class, Fields: map[string]Object{}}
+}
+
+func (i *Instance) Get(field string) (Object, bool) {
+	val, ok := i.Fields[field]
+	return val, ok
+}
+
+func (i *Instance) Set(field string, val Object) {
+	if _, ok := i.Fields[field]; !ok {
+		i.order = append(i.order, field)
+	}
+	i.Fields[field] = val
+}
+
+func (i *Instance) Type() ObjectType { return INSTANCE_OBJ }
+func (i *Instance) Inspect() string {
+	var out bytes.Buffer
+
+	fields := []string{}
+	for _, name := range i.order {
+		fields = append(fields, name+": "+i.Fields[name].Inspect())
+	}
+
+	out.WriteString(i.Class.Name)
+	out.WriteString("{")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// Super is what mool refers to inside a method: the same instance, with
+// methods looked up from the parent of the class defining that method
+type Super struct {
+	Instance *Instance
+	Class    *Class
+}
+
+func (s *Super) Type() ObjectType { return SUPER_OBJ }
+func (s *Super) Inspect() string  { return "mool " + s.Class.Name }
+
+// Enum is what a ganana declaration binds: a fixed, ordered set of variants
+type Enum struct {
+	Name     string
+	Variants []*EnumValue
+}
+
+func NewEnum(name string, variants []string) *Enum {
+	enum := &Enum{Name: name}
+	for i, variant := range variants {
+		enum.Variants = append(enum.Variants, &EnumValue{Enum: enum, Name: variant, Ordinal: i})
+	}
+
+	return enum
+}
+
+func (e *Enum) Variant(name string) (*EnumValue, bool) {
+	for _, variant := range e.Variants {
+		if variant.Name == name {
+			return variant, true
+		}
+	}
+
+	return nil, false
+}
+
+func (e *Enum) Type() ObjectType { return ENUM_OBJ }
+func (e *Enum) Inspect() string {
+	names := []string{}
+	for _, variant := range e.Variants {
+		names = append(names, variant.Name)
+	}
+
+	return "ganana " + e.Name + " { " + strings.Join(names, ", ") + " }"
+}
+
+// EnumValue is one variant of an Enum. There is exactly one of each, so it
+// is equal only to itself
+type EnumValue struct {
+	Enum    *Enum
+	Name    string
+	Ordinal int
+}
+
+func (ev *EnumValue) Type() ObjectType { return ENUM_VALUE_OBJ }
+func (ev *EnumValue) Inspect() string  { return ev.Enum.Name + "." + ev.Name }
+
+// hashes by the enum's identity, so same-named variants of two gananas
+// don't share a key
+func (ev *EnumValue) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(fmt.Sprintf("%p/%d", ev.Enum, ev.Ordinal)))
+
+	return HashKey{Type: ev.Type(), Value: h.Sum64()}
+}
+
 type Hashable interface {
 	HashKey() HashKey
 }
@@ -209,25 +656,18 @@ func (s *String) HashKey() HashKey {
 	return HashKey{Type: s.Type(), Value: h.Sum64()}
 }
 
-type HashPair struct {
-	Key   Object
-	Value Object
+func (c *Char) HashKey() HashKey {
+	return HashKey{Type: c.Type(), Value: uint64(c.Value)}
 }
 
-type Hash struct {
-	Pairs map[HashKey]HashPair
-}
-
-func (h *Hash) Type() ObjectType { return HASH_OBJ }
-func (h *Hash) Inspect() string {
-	var out bytes.Buffer
-	pairs := []string{}
-	for _, pair := range h.Pairs {
-		pairs = append(pairs, fmt.Sprintf("%s: %s",
-			pair.Key.Inspect(), pair.Value.Inspect()))
+// a BigInt holding an int64 equals, and so hashes like, that Integer
+func (bi *BigInt) HashKey() HashKey {
+	if bi.Value.IsInt64() {
+		return (&Integer{Value: bi.Value.Int64()}).HashKey()
 	}
-	out.WriteString("{")
-	out.WriteString(strings.Join(pairs, ", "))
-	out.WriteString("}")
-	return out.String()
+
+	h := fnv.New64a()
+	h.Write([]byte(bi.Value.String()))
+
+	return HashKey{Type: bi.Type(), Value: h.Sum64()}
 }
@@ -1,6 +1,18 @@
 package object
 
-import "testing"
+import (
+	"math"
+	"math/big"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// newRange is NewRange for ranges known to fit
+func newRange(start, stop, step int64) *Range {
+	r, _ := NewRange(start, stop, step)
+	return r
+}
 
 func TestStringHashKey(t *testing.T) {
 	hello1 := &String{Value: "namaste duniya"}
@@ -20,3 +32,508 @@ func TestStringHashKey(t *testing.T) {
 		t.Errorf("strings with different content have same hash keys")
 	}
 }
+
+func TestHashKeyOf(t *testing.T) {
+	arr := func(elements ...Object) *Array { return &Array{Elements: elements} }
+	one, two := &Integer{Value: 1}, &Integer{Value: 2}
+	point := &RecordType{Name: "Bindu", Fields: []string{"x", "y"}}
+	record := func(x, y Object) *Record {
+		return &Record{RecordType: point, Fields: map[string]Object{"x": x, "y": y}}
+	}
+
+	same := [][2]Object{
+		{arr(one, two), arr(&Integer{Value: 1}, &Integer{Value: 2})},
+		{arr(arr(one), &String{Value: "a"}), arr(arr(one), &String{Value: "a"})},
+		{record(one, two), record(one, two)},
+		{one, &Decimal{Value: big.NewInt(100), Scale: 2}},
+		{one, &BigInt{Value: big.NewInt(1)}},
+		{&BigInt{Value: new(big.Int).Lsh(big.NewInt(1), 64)}, &Decimal{Value: new(big.Int).Lsh(big.NewInt(10), 64), Scale: 1}},
+	}
+	for _, pair := range same {
+		a, ok := HashKeyOf(pair[0])
+		b, ok2 := HashKeyOf(pair[1])
+		if !ok || !ok2 || a != b {
+			t.Errorf("equal values %s and %s hash differently", pair[0].Inspect(), pair[1].Inspect())
+		}
+	}
+
+	different := [][2]Object{
+		{arr(one, two), arr(two, one)},
+		{arr(one), one},
+		{arr(arr(one, two)), arr(arr(one), arr(two))},
+		{record(one, two), record(two, one)},
+	}
+	for _, pair := range different {
+		a, _ := HashKeyOf(pair[0])
+		b, _ := HashKeyOf(pair[1])
+		if a == b {
+			t.Errorf("different values %s and %s hash alike", pair[0].Inspect(), pair[1].Inspect())
+		}
+	}
+
+	for _, obj := range []Object{NewHash(), arr(one, NewHash()), &Function{}, record(one, NewHash())} {
+		if _, ok := HashKeyOf(obj); ok {
+			t.Errorf("%s should not be hashable", obj.Inspect())
+		}
+	}
+
+	// storing a key freezes the records in it, but looking one up doesn't
+	inner, outer, probe := record(one, two), record(one, two), record(one, two)
+	h := NewHash()
+	h.Set(arr(outer, record(inner, two)), one)
+	if _, ok := h.Get(arr(probe, record(record(one, two), two))); !ok {
+		t.Errorf("equal key not found")
+	}
+	if !outer.Frozen || !inner.Frozen || probe.Frozen {
+		t.Errorf("wrong records frozen. outer=%t, inner=%t, probe=%t", outer.Frozen, inner.Frozen, probe.Frozen)
+	}
+}
+
+// collider hashes every value alike, to force keys into one bucket
+type collider struct{ name string }
+
+func (c *collider) Type() ObjectType { return "COLLIDER" }
+func (c *collider) Inspect() string  { return c.name }
+func (c *collider) HashKey() HashKey { return HashKey{Type: c.Type(), Value: 42} }
+func (c *collider) Equals(o Object) bool {
+	other, ok := o.(*collider)
+	return ok && c.name == other.name
+}
+
+func TestHashCollisions(t *testing.T) {
+	h := NewHash()
+	h.Set(&collider{"a"}, &Integer{Value: 1})
+	h.Set(&collider{"b"}, &Integer{Value: 2})
+	h.Set(&collider{"a"}, &Integer{Value: 3})
+
+	if h.Len() != 2 {
+		t.Fatalf("hash has wrong length. got=%d", h.Len())
+	}
+	if h.Inspect() != "{a: 3, b: 2}" {
+		t.Errorf("hash.Inspect() wrong. got=%q", h.Inspect())
+	}
+
+	pair, ok := h.Get(&collider{"b"})
+	if !ok || pair.Value.Inspect() != "2" {
+		t.Errorf("wrong pair for colliding key b. got=%v, %t", pair, ok)
+	}
+	if _, ok := h.Get(&collider{"c"}); ok {
+		t.Errorf("found a pair for missing colliding key c")
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	set := func(values ...int64) *Set {
+		s := NewSet()
+		for _, v := range values {
+			s.Add(&Integer{Value: v})
+		}
+		return s
+	}
+
+	tests := []struct {
+		result   *Set
+		expected string
+	}{
+		{set(1, 2, 2, 3), "{1, 2, 3}"},
+		{set(), "samuchchay()"},
+		{set(1, 2).Union(set(2, 3)), "{1, 2, 3}"},
+		{set(1, 2, 3).Intersection(set(3, 2, 5)), "{2, 3}"},
+		{set(1, 2, 3).Difference(set(2)), "{1, 3}"},
+		{set(1, 2, 3).SymmetricDifference(set(3, 4)), "{1, 2, 4}"},
+	}
+
+	for _, tt := range tests {
+		if tt.result.Inspect() != tt.expected {
+			t.Errorf("wrong set. expected=%q, got=%q", tt.expected, tt.result.Inspect())
+		}
+	}
+
+	if !Equals(set(1, 2), set(2, 1)) {
+		t.Errorf("sets with the same elements are not equal")
+	}
+	if Equals(set(1, 2), set(1)) {
+		t.Errorf("sets with different elements are equal")
+	}
+}
+
+func TestEquals(t *testing.T) {
+	arr := func(elements ...Object) *Array { return &Array{Elements: elements} }
+	fn := &Function{}
+
+	tests := []struct {
+		a, b     Object
+		expected bool
+	}{
+		{&Integer{Value: 1}, &Integer{Value: 1}, true},
+		{&Integer{Value: 1}, &String{Value: "1"}, false},
+		{&Null{}, &Null{}, true},
+		{&Null{}, &Integer{Value: 0}, false},
+		{&Boolean{Value: true}, &Boolean{Value: true}, true},
+		{arr(&Integer{Value: 1}, arr(&String{Value: "a"})), arr(&Integer{Value: 1}, arr(&String{Value: "a"})), true},
+		{arr(&Integer{Value: 1}), arr(&Integer{Value: 1}, &Integer{Value: 2}), false},
+		{fn, fn, true},
+		{fn, &Function{}, false},
+		{newRange(1, 4, 1), newRange(1, 4, 1), true},
+		{newRange(5, 5, 1), newRange(7, 2, 1), true},
+	}
+
+	for i, tt := range tests {
+		if got := Equals(tt.a, tt.b); got != tt.expected {
+			t.Errorf("tests[%d] - Equals(%s, %s) wrong. expected=%t, got=%t",
+				i, tt.a.Inspect(), tt.b.Inspect(), tt.expected, got)
+		}
+	}
+}
+
+func TestRanges(t *testing.T) {
+	tests := []struct {
+		start, stop, step int64
+		length            int64
+		inspect           string
+	}{
+		{1, 4, 1, 3, "1..3"},
+		{0, 10, 3, 4, "(0..9)[::3]"},
+		{5, 0, -2, 3, "(1..5)[::-2]"},
+		{4, 1, 1, 0, "4..<4"},
+		{0, 3, math.MaxInt64, 1, "(0..0)[::9223372036854775807]"},
+		{2, -1, math.MinInt64, 1, "(2..2)[::-9223372036854775808]"},
+		{math.MinInt64 + 1, 0, 1, math.MaxInt64, "-9223372036854775807..-1"},
+		{math.MinInt64, math.MaxInt64, 3, 6148914691236517205, "(-9223372036854775808..9223372036854775804)[::3]"},
+	}
+
+	for _, tt := range tests {
+		r, ok := NewRange(tt.start, tt.stop, tt.step)
+		if !ok {
+			t.Errorf("NewRange(%d, %d, %d) failed", tt.start, tt.stop, tt.step)
+			continue
+		}
+		if r.Len() != tt.length {
+			t.Errorf("NewRange(%d, %d, %d).Len() wrong. expected=%d, got=%d", tt.start, tt.stop, tt.step, tt.length, r.Len())
+		}
+		if r.Inspect() != tt.inspect {
+			t.Errorf("NewRange(%d, %d, %d).Inspect() wrong. expected=%q, got=%q", tt.start, tt.stop, tt.step, tt.inspect, r.Inspect())
+		}
+	}
+
+	if _, ok := NewRange(math.MinInt64, 0, 1); ok {
+		t.Errorf("NewRange built a range longer than an int64 can count")
+	}
+
+	r := newRange(math.MinInt64, math.MaxInt64, 3)
+	for n, expected := range map[int64]bool{math.MinInt64: true, math.MinInt64 + 1: false, math.MaxInt64 - 3: true, math.MaxInt64 - 1: false, 0: false, -2: true} {
+		if r.Contains(n) != expected {
+			t.Errorf("%s contains %d wrong. expected=%t", r.Inspect(), n, expected)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	arr := func(elements ...Object) *Array { return &Array{Elements: elements} }
+
+	tests := []struct {
+		a, b       Object
+		expected   int
+		comparable bool
+	}{
+		{&Integer{Value: 1}, &Integer{Value: 2}, -1, true},
+		{&String{Value: "b"}, &String{Value: "a"}, 1, true},
+		{arr(&Integer{Value: 1}, &Integer{Value: 2}), arr(&Integer{Value: 1}, &Integer{Value: 3}), -1, true},
+		{arr(&Integer{Value: 1}), arr(&Integer{Value: 1}, &Integer{Value: 0}), -1, true},
+		{arr(&Integer{Value: 1}), arr(&Integer{Value: 1}), 0, true},
+		{arr(&Integer{Value: 1}), arr(&String{Value: "1"}), 0, false},
+		{&Integer{Value: 1}, &String{Value: "1"}, 0, false},
+		{&Boolean{Value: true}, &Boolean{Value: false}, 0, false},
+	}
+
+	for i, tt := range tests {
+		got, ok := Compare(tt.a, tt.b)
+		if ok != tt.comparable {
+			t.Errorf("tests[%d] - Compare(%s, %s) comparability wrong. expected=%t, got=%t",
+				i, tt.a.Inspect(), tt.b.Inspect(), tt.comparable, ok)
+			continue
+		}
+		if ok && sign(got) != tt.expected {
+			t.Errorf("tests[%d] - Compare(%s, %s) wrong. expected=%d, got=%d",
+				i, tt.a.Inspect(), tt.b.Inspect(), tt.expected, got)
+		}
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func TestCollation(t *testing.T) {
+	tests := []struct {
+		collation Collation
+		a, b      string
+		expected  int
+	}{
+		{BYTE_COLLATION, "aam", "kela", -1},
+		{BYTE_COLLATION, "कमल", "अमर", 1},
+		{VARNAMALA_COLLATION, "अमर", "आम", -1},
+		{VARNAMALA_COLLATION, "कमल", "काम", -1},
+		{VARNAMALA_COLLATION, "कौन", "क्या", -1},
+		{VARNAMALA_COLLATION, "संत", "सकल", -1},
+		// क़ sorts at the end of the block in code point order but beside क in a dictionary
+		{BYTE_COLLATION, "क़लम", "खत", 1},
+		{VARNAMALA_COLLATION, "क़लम", "खत", -1},
+		{VARNAMALA_COLLATION, "ज़मीन", "जमीन", 1},
+		{VARNAMALA_COLLATION, "हँसी", "हंस", 1},
+		{VARNAMALA_COLLATION, "डॉक्टर", "डोर", -1},
+		{VARNAMALA_COLLATION, "जमीन", "जमीन", 0},
+	}
+
+	for i, tt := range tests {
+		if got := sign(tt.collation.Compare(tt.a, tt.b)); got != tt.expected {
+			t.Errorf("tests[%d] - Compare(%q, %q) wrong. expected=%d, got=%d",
+				i, tt.a, tt.b, tt.expected, got)
+		}
+	}
+
+	if _, ok := LookupCollation("varnamala"); !ok {
+		t.Errorf("varnamala collation not found")
+	}
+	if _, ok := LookupCollation("klingon"); ok {
+		t.Errorf("unknown collation found")
+	}
+}
+
+func TestDecimalInspect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"0", "0"},
+		{"12.50", "12.50"},
+		{"0.05", "0.05"},
+		{"-0.5", "-0.5"},
+		{"999", "999"},
+		{"1000", "1,000"},
+		{"123456.78", "1,23,456.78"},
+		{"12345678.90", "1,23,45,678.90"},
+		{"-100000000", "-10,00,00,000"},
+	}
+
+	for _, tt := range tests {
+		d, ok := ParseDecimal(tt.input)
+		if !ok {
+			t.Fatalf("could not parse %q", tt.input)
+		}
+		if d.Inspect() != tt.expected {
+			t.Errorf("wrong Inspect for %q. expected=%q, got=%q", tt.input, tt.expected, d.Inspect())
+		}
+	}
+
+	for _, invalid := range []string{"", ".", "1.", ".5", "1.2.3", "12a", "--1"} {
+		if _, ok := ParseDecimal(invalid); ok {
+			t.Errorf("parsed invalid decimal %q", invalid)
+		}
+	}
+}
+
+func TestDecimalRound(t *testing.T) {
+	tests := []struct {
+		input    string
+		places   int32
+		mode     RoundingMode
+		expected string
+	}{
+		{"2.345", 2, ROUND_HALF_UP, "2.35"},
+		{"2.345", 2, ROUND_HALF_EVEN, "2.34"},
+		{"2.355", 2, ROUND_HALF_EVEN, "2.36"},
+		{"2.345", 2, ROUND_HALF_DOWN, "2.34"},
+		{"2.341", 2, ROUND_UP, "2.35"},
+		{"2.349", 2, ROUND_DOWN, "2.34"},
+		{"-2.345", 2, ROUND_HALF_UP, "-2.35"},
+		{"-2.341", 2, ROUND_CEILING, "-2.34"},
+		{"-2.341", 2, ROUND_FLOOR, "-2.35"},
+		{"2.341", 2, ROUND_CEILING, "2.35"},
+		{"-0.4", 0, ROUND_HALF_UP, "0"},
+		{"-0.5", 0, ROUND_HALF_UP, "-1"},
+		{"12.5", 2, ROUND_HALF_UP, "12.50"},
+	}
+
+	for _, tt := range tests {
+		d, _ := ParseDecimal(tt.input)
+		if got := d.Round(tt.places, tt.mode).Inspect(); got != tt.expected {
+			t.Errorf("wrong rounding of %s to %d places. expected=%q, got=%q",
+				tt.input, tt.places, tt.expected, got)
+		}
+	}
+}
+
+func TestDecimalQuo(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected string
+	}{
+		{"10.00", "4", "2.50"},
+		{"1", "8", "0.125"},
+		{"1", "3", "0.3333333333333333333333333333"},
+		{"2", "3", "0.6666666666666666666666666667"},
+		{"-1", "3", "-0.3333333333333333333333333333"},
+	}
+
+	for _, tt := range tests {
+		a, _ := ParseDecimal(tt.a)
+		b, _ := ParseDecimal(tt.b)
+		if got := a.Quo(b, ROUND_HALF_EVEN).Inspect(); got != tt.expected {
+			t.Errorf("wrong quotient of %s / %s. expected=%q, got=%q", tt.a, tt.b, tt.expected, got)
+		}
+	}
+}
+
+func TestIterators(t *testing.T) {
+	one, two := &Integer{Value: 1}, &Integer{Value: 2}
+	hash := NewHash()
+	hash.Set(&String{Value: "b"}, one)
+	hash.Set(&String{Value: "a"}, two)
+
+	tests := []struct {
+		iterable Iterable
+		expected []string
+	}{
+		{&Array{Elements: []Object{one, two}}, []string{"1", "2"}},
+		{&Tuple{Elements: []Object{two}}, []string{"2"}},
+		{&String{Value: "कि"}, []string{"क", "ि"}},
+		{newRange(5, 0, -2), []string{"5", "3", "1"}},
+		{hash, []string{"b", "a"}},
+		{&Array{}, []string{}},
+		{NewGenerator("g", func(yield func(Object) bool, stdout *[]string) Object {
+			for i := int64(0); i < 3; i++ {
+				if !yield(&Integer{Value: i}) {
+					break
+				}
+			}
+			return nil
+		}), []string{"0", "1", "2"}},
+	}
+
+	stdout := []string{}
+	for _, tt := range tests {
+		got := []string{}
+		it := tt.iterable.Iter()
+		for el, ok := it.Next(&stdout); ok; el, ok = it.Next(&stdout) {
+			got = append(got, el.Inspect())
+		}
+
+		if len(got) != len(tt.expected) {
+			t.Errorf("wrong elements for %s. expected=%q, got=%q", tt.iterable.Inspect(), tt.expected, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.expected[i] {
+				t.Errorf("wrong elements for %s. expected=%q, got=%q", tt.iterable.Inspect(), tt.expected, got)
+				break
+			}
+		}
+		if _, ok := it.Next(&stdout); ok {
+			t.Errorf("%s iterator not exhausted", tt.iterable.Inspect())
+		}
+	}
+
+	failing := NewGenerator("", func(yield func(Object) bool, stdout *[]string) Object {
+		yield(one)
+		return &Error{Message: "ruko"}
+	})
+	failing.Next(&stdout)
+	if err, ok := failing.Next(&stdout); !ok || err.Inspect() != (&Error{Message: "ruko"}).Inspect() {
+		t.Errorf("generator error not returned. got=%v, %t", err, ok)
+	}
+	if _, ok := failing.Next(&stdout); ok {
+		t.Errorf("generator not done after its error")
+	}
+}
+
+func TestGeneratorClose(t *testing.T) {
+	var gen *Generator
+	gen = NewGenerator("", func(yield func(Object) bool, stdout *[]string) Object {
+		*stdout = append(*stdout, "shuru")
+		if next, ok := gen.Next(stdout); !ok || next.(*Error).Message != "generator already running" {
+			t.Errorf("generator resumed itself. got=%v, %t", next, ok)
+		}
+		for i := int64(0); yield(&Integer{Value: i}); i++ {
+		}
+		*stdout = append(*stdout, "band")
+		return nil
+	})
+
+	first, second := []string{}, []string{}
+	gen.Next(&first)
+	gen.Next(&second)
+	gen.Close(&second)
+
+	if len(first) != 1 || first[0] != "shuru" {
+		t.Errorf("output of the first resume wrong. got=%q", first)
+	}
+	// Close waits for the body to return, so its output is already there
+	if len(second) != 1 || second[0] != "band" {
+		t.Errorf("output of the second resume and Close wrong. got=%q", second)
+	}
+	if _, ok := gen.Next(&second); ok {
+		t.Errorf("closed generator resumed")
+	}
+
+	unstarted := NewGenerator("", func(yield func(Object) bool, stdout *[]string) Object {
+		t.Errorf("body of a generator closed before starting ran")
+		return nil
+	})
+	unstarted.Close(&first)
+	if _, ok := unstarted.Next(&first); ok {
+		t.Errorf("closed generator resumed")
+	}
+}
+
+func TestTrackGenerators(t *testing.T) {
+	env := NewEnclosedEnvironment(NewEnvironment())
+	stdout := []string{}
+	endless := func(name string) *Generator {
+		gen := NewGenerator(name, func(yield func(Object) bool, stdout *[]string) Object {
+			for yield(&Integer{Value: 1}) {
+			}
+			*stdout = append(*stdout, name)
+			return nil
+		})
+		env.Track(gen, &stdout)
+		return gen
+	}
+
+	kept := []*Generator{endless("pehla"), endless("dusra")}
+	for _, gen := range kept {
+		gen.Next(&stdout)
+	}
+
+	// one nothing refers to any more is closed once it is collected, which
+	// the garbage collector reports in its own time
+	for i := 0; i < 3; i++ {
+		endless("chhoda").Next(&stdout)
+	}
+	for i := 0; i < 100 && len(stdout) < 3; i++ {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+		env.CloseAbandonedGenerators(&stdout)
+	}
+
+	endless("anchhua")
+	env.CloseGenerators(&stdout)
+	runtime.KeepAlive(kept)
+
+	expected := []string{"chhoda", "chhoda", "chhoda", "pehla", "dusra"}
+	if len(stdout) != len(expected) {
+		t.Fatalf("wrong output closing generators. expected=%q, got=%q", expected, stdout)
+	}
+	for i := range expected {
+		if stdout[i] != expected[i] {
+			t.Fatalf("wrong output closing generators. expected=%q, got=%q", expected, stdout)
+		}
+	}
+}
@@ -1,8 +1,12 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/Suryansh-23/amrit/ast"
 	"github.com/Suryansh-23/amrit/lexer"
@@ -13,30 +17,47 @@ const (
 	_ int = iota // gives the following const. a no. from 1 to 7 (i.e. their precedence)
 	LOWEST
 	EQUALS      // ==
-	LESSGREATER // > or <
+	LESSGREATER // > or < or mein
+	PIPE        // x |> f
 	SLICE       // myArray[X:Y]
+	RANGE       // X..Y or X..<Y
+	BIT_XOR     // ^
+	BIT_AND     // &
+	SHIFT       // << or >>
 	SUM         // +
 	PRODUCT     // *
 	PREFIX      // -X or !X
+	POWER       // X ** Y, binds tighter than a prefix operator: -2 ** 2 == -(2 ** 2)
 	CALL        // myFunction(X)
 	INDEX       // myArray[X]
 )
 
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       LESSGREATER,
-	token.LT_EQ:    LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.GT_EQ:    LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.MODULO:   PRODUCT,
-	token.LPAREN:   CALL,
-	token.LBRACKET: INDEX,
-	token.COLON:    SLICE,
+	token.EQ:          EQUALS,
+	token.NOT_EQ:      EQUALS,
+	token.LT:          LESSGREATER,
+	token.LT_EQ:       LESSGREATER,
+	token.GT:          LESSGREATER,
+	token.GT_EQ:       LESSGREATER,
+	token.IN_LATIN:    LESSGREATER,
+	token.PIPE:        PIPE,
+	token.PLUS:        SUM,
+	token.MINUS:       SUM,
+	token.SLASH:       PRODUCT,
+	token.ASTERISK:    PRODUCT,
+	token.MODULO:      PRODUCT,
+	token.FLOOR_DIV:   PRODUCT,
+	token.POWER:       POWER,
+	token.AMPERSAND:   BIT_AND,
+	token.CARET:       BIT_XOR,
+	token.SHIFT_LEFT:  SHIFT,
+	token.SHIFT_RIGHT: SHIFT,
+	token.LPAREN:      CALL,
+	token.LBRACKET:    INDEX,
+	token.DOT:         INDEX,
+	token.COLON:       SLICE,
+	token.RANGE:       RANGE,
+	token.RANGE_EX:    RANGE,
 }
 
 type (
@@ -45,8 +66,22 @@ type (
 )
 
 type Parser struct {
-	l      *lexer.Lexer
-	errors []string
+	l        *lexer.Lexer
+	errors   []string
+	warnings []string
+
+	// the variants of each ganana declared so far, and every milao parsed,
+	// so milaos can be checked for variants they leave unhandled
+	enums   map[string][]string
+	matches []*ast.MatchExpression
+
+	// set while parsing a milao pattern, where => ends the pattern rather
+	// than starting an arrow function
+	inPattern bool
+
+	// while parsing a function body, records whether it has an upaj; nil
+	// outside any function
+	yields *bool
 
 	curToken  token.Token
 	peekToken token.Token
@@ -64,23 +99,32 @@ func (p *Parser) registerInfix(tokenType token.TokenType, fn infixParseFn) {
 }
 
 func New(l *lexer.Lexer) *Parser {
-	p := &Parser{l: l, errors: []string{}}
+	p := &Parser{l: l, errors: []string{}, warnings: []string{}, enums: map[string][]string{}}
 
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
+	p.registerPrefix(token.SELF_LATIN, p.parseIdentifier)
+	p.registerPrefix(token.SUPER_LATIN, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.DECIMAL, p.parseDecimalLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.CHAR, p.parseCharLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.TILDE, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE_LATIN, p.parseBoolean)
 	p.registerPrefix(token.FALSE_LATIN, p.parseBoolean)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.IF_LATIN, p.parseIfExpression)
 	p.registerPrefix(token.WHILE_LATIN, p.parseWhileExpression)
+	p.registerPrefix(token.FOR_LATIN, p.parseForEachExpression)
+	p.registerPrefix(token.MATCH_LATIN, p.parseMatchExpression)
+	p.registerPrefix(token.TRY_LATIN, p.parseTryExpression)
 	p.registerPrefix(token.FN_LATIN, p.parseFnLiteral)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
+	p.registerPrefix(token.ELLIPSIS, p.parseSpreadExpression)
 	p.registerPrefix(token.SINGLE_COMMENT, p.parseComment)
 	p.registerPrefix(token.MULTI_COMMENT, p.parseComment)
 
@@ -91,14 +135,25 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.SLASH, p.parseInfixExpression)
 	p.registerInfix(token.ASTERISK, p.parseInfixExpression)
 	p.registerInfix(token.MODULO, p.parseInfixExpression)
+	p.registerInfix(token.FLOOR_DIV, p.parseInfixExpression)
+	p.registerInfix(token.POWER, p.parseInfixExpression)
+	p.registerInfix(token.AMPERSAND, p.parseInfixExpression)
+	p.registerInfix(token.CARET, p.parseInfixExpression)
+	p.registerInfix(token.SHIFT_LEFT, p.parseInfixExpression)
+	p.registerInfix(token.SHIFT_RIGHT, p.parseInfixExpression)
 	p.registerInfix(token.EQ, p.parseInfixExpression)
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.LT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
 	p.registerInfix(token.GT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.IN_LATIN, p.parseInfixExpression)
+	p.registerInfix(token.RANGE, p.parseInfixExpression)
+	p.registerInfix(token.RANGE_EX, p.parseInfixExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
+	p.registerInfix(token.PIPE, p.parsePipeExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexSliceExpression)
+	p.registerInfix(token.DOT, p.parseMemberExpression)
 	p.registerInfix(token.COLON, p.parseSliceExpression)
 
 	p.nextToken()
@@ -111,6 +166,12 @@ func (p *Parser) Errors() []string {
 	return p.errors
 }
 
+// Warnings are problems that don't stop a program from running, such as a
+// milao that leaves some variants of a ganana unhandled
+func (p *Parser) Warnings() []string {
+	return p.warnings
+}
+
 func (p *Parser) peekError(t token.TokenType) {
 	msg := fmt.Sprintf("expected next token to be %s, but got %s instead", t, p.curToken.Type)
 	p.errors = append(p.errors, msg)
@@ -119,6 +180,27 @@ func (p *Parser) peekError(t token.TokenType) {
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
+
+	// a comment where a statement may start is a statement of its own; one
+	// anywhere else sits inside a statement, such as before a line the
+	// statement carries on to, and is dropped
+	for isComment(p.peekToken.Type) && !p.atStatementStart() {
+		p.peekToken = p.l.NextToken()
+	}
+}
+
+// reports whether a statement may start after the current token
+func (p *Parser) atStatementStart() bool {
+	switch p.curToken.Type {
+	case "", token.TERM, token.SINGLE_COMMENT, token.MULTI_COMMENT:
+		return true
+	default:
+		return false
+	}
+}
+
+func isComment(t token.TokenType) bool {
+	return t == token.SINGLE_COMMENT || t == token.MULTI_COMMENT
 }
 
 func (p *Parser) ParseProgram() *ast.Program {
@@ -131,17 +213,38 @@ func (p *Parser) ParseProgram() *ast.Program {
 		}
 		p.nextToken()
 	}
+
+	for _, match := range p.matches {
+		if missing, enum, ok := p.missingVariants(match); ok {
+			msg := fmt.Sprintf("milao over %s does not handle %s (line %d, column %d)",
+				enum, strings.Join(missing, ", "), match.Token.Line, match.Token.Column)
+			p.warnings = append(p.warnings, msg)
+		}
+	}
+
 	return program
 }
 
 func (p *Parser) parseIdentifier() ast.Expression {
-	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if p.curTokenIs(token.IDENT) && p.peekTokenIs(token.ARROW) && !p.inPattern {
+		p.nextToken()
+		return p.parseArrowFunction([]ast.Expression{ident})
+	}
+
+	return ident
 }
 
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		if value, ok := new(big.Int).SetString(p.curToken.Literal, 0); ok {
+			return &ast.BigIntegerLiteral{Token: p.curToken, Value: value}
+		}
+	}
 	if err != nil {
 		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
 		p.errors = append(p.errors, msg)
@@ -152,29 +255,57 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	return lit
 }
 
+func (p *Parser) parseDecimalLiteral() ast.Expression {
+	return &ast.DecimalLiteral{Token: p.curToken, Value: strings.TrimSuffix(p.curToken.Literal, "d")}
+}
+
 func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+func (p *Parser) parseCharLiteral() ast.Expression {
+	value, size := utf8.DecodeRuneInString(p.curToken.Literal)
+	if size == 0 || size != len(p.curToken.Literal) {
+		msg := fmt.Sprintf("character literal must hold exactly one character, got '%s'", p.curToken.Literal)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
+	return &ast.CharLiteral{Token: p.curToken, Value: value}
+}
+
 func (p *Parser) parseStatement() ast.Statement {
+	if isComment(p.curToken.Type) {
+		return p.parseCommentStatement()
+	}
+
 	switch p.curToken.Literal {
 	case token.LET_LATIN:
 		return p.parseLetStatement()
 	case token.RETURN_LATIN:
 		return p.parseReturnStatement()
+	case token.THROW_LATIN:
+		return p.parseThrowStatement()
+	case token.YIELD_LATIN:
+		return p.parseYieldStatement()
+	case token.FN_LATIN:
+		if p.peekTokenIs(token.IDENT) {
+			return p.parseFunctionStatement()
+		}
+		return p.parseExpressionStatement()
+	case token.STRUCT_LATIN:
+		return p.parseStructStatement()
+	case token.CLASS_LATIN:
+		return p.parseClassStatement()
+	case token.ENUM_LATIN:
+		return p.parseEnumStatement()
 	default:
 		if p.curToken.Type == token.IDENT {
-			switch p.peekToken.Type {
-			case token.ASSIGN:
+			switch {
+			case p.peekTokenIs(token.ASSIGN):
 				return p.parseIdentifierReassign()
-			case token.PLUS_EQ:
-				return p.parseCompoundAssignment(token.PLUS_EQ)
-			case token.MINUS_EQ:
-				return p.parseCompoundAssignment(token.MINUS_EQ)
-			case token.ASTERISK_EQ:
-				return p.parseCompoundAssignment(token.ASTERISK_EQ)
-			case token.SLASH_EQ:
-				return p.parseCompoundAssignment(token.SLASH_EQ)
+			case isCompoundAssignment(p.peekToken.Type):
+				return p.parseCompoundAssignment(p.peekToken.Type)
 			default:
 				return p.parseExpressionStatement()
 			}
@@ -184,21 +315,56 @@ func (p *Parser) parseStatement() ast.Statement {
 	}
 }
 
+func isCompoundAssignment(t token.TokenType) bool {
+	switch t {
+	case token.PLUS_EQ, token.MINUS_EQ, token.ASTERISK_EQ, token.SLASH_EQ,
+		token.MODULO_EQ, token.POWER_EQ, token.FLOOR_DIV_EQ, token.AMPERSAND_EQ,
+		token.CARET_EQ, token.SHIFT_LEFT_EQ, token.SHIFT_RIGHT_EQ:
+		return true
+	default:
+		return false
+	}
+}
+
 func (p *Parser) parseLetStatement() *ast.LetStatement {
 	stmt := &ast.LetStatement{Token: p.curToken}
 
-	if !p.expectPeek(token.IDENT) {
-		return nil
-	}
+	switch p.peekToken.Type {
+	case token.LBRACKET, token.LBRACE, token.LPAREN:
+		p.nextToken()
 
-	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		stmt.Pattern = p.parsePattern()
+		if stmt.Pattern == nil {
+			return nil
+		}
+	default:
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+		// mana q, r = ... unpacks a tuple
+		if p.peekTokenIs(token.COMMA) {
+			stmt.Pattern = p.parseBareTuple(stmt.Name)
+			stmt.Name = nil
+			if stmt.Pattern == nil {
+				return nil
+			}
+			if !p.checkPattern(stmt.Pattern) {
+				msg := fmt.Sprintf("invalid destructuring pattern: %s", stmt.Pattern.String())
+				p.errors = append(p.errors, msg)
+				return nil
+			}
+		}
+	}
 
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
 
 	p.nextToken()
-	stmt.Value = p.parseExpression(LOWEST)
+	stmt.Value = p.parseBareTuple(p.parseExpression(LOWEST))
 
 	if p.peekTokenIs(token.TERM) {
 		p.nextToken()
@@ -216,7 +382,7 @@ func (p *Parser) parseIdentifierReassign() *ast.LetStatement {
 	}
 
 	p.nextToken()
-	stmt.Value = p.parseExpression(LOWEST)
+	stmt.Value = p.parseBareTuple(p.parseExpression(LOWEST))
 
 	if p.peekTokenIs(token.TERM) {
 		p.nextToken()
@@ -249,7 +415,44 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 
 	p.nextToken()
 
-	stmt.ReturnValue = p.parseExpression(LOWEST)
+	stmt.ReturnValue = p.parseBareTuple(p.parseExpression(LOWEST))
+
+	if p.peekTokenIs(token.TERM) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseYieldStatement() *ast.YieldStatement {
+	stmt := &ast.YieldStatement{Token: p.curToken}
+
+	if p.yields == nil {
+		p.errors = append(p.errors, "upaj outside a karya")
+		return nil
+	}
+	*p.yields = true
+
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+	if stmt.Value == nil {
+		return nil
+	}
+
+	if p.peekTokenIs(token.TERM) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseThrowStatement() *ast.ThrowStatement {
+	stmt := &ast.ThrowStatement{Token: p.curToken}
+
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
 
 	if p.peekTokenIs(token.TERM) {
 		p.nextToken()
@@ -258,16 +461,217 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	return stmt
 }
 
-func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
+func (p *Parser) parseExpressionStatement() ast.Statement {
 	stmt := &ast.ExpressionStatement{Token: p.curToken}
 	stmt.Expression = p.parseExpression(LOWEST)
 
+	if member, ok := stmt.Expression.(*ast.MemberExpression); ok {
+		if p.peekTokenIs(token.ASSIGN) || isCompoundAssignment(p.peekToken.Type) {
+			return p.parseMemberAssignment(member)
+		}
+	}
+
+	if p.peekTokenIs(token.TERM) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+// a comment on a line of its own ends there, so whatever the next line starts
+// with, even ( or -, begins a new statement rather than carrying the comment on
+func (p *Parser) parseCommentStatement() *ast.ExpressionStatement {
+	stmt := &ast.ExpressionStatement{Token: p.curToken, Expression: p.parseComment()}
+
+	if p.peekTokenIs(token.TERM) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseMemberAssignment(target *ast.MemberExpression) *ast.MemberAssignStatement {
+	p.nextToken()
+	stmt := &ast.MemberAssignStatement{Token: p.curToken, Target: target, Operator: p.curToken.Literal}
+
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.TERM) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseStructStatement() *ast.StructStatement {
+	stmt := &ast.StructStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	for !p.peekTokenIs(token.RBRACE) && !p.peekTokenIs(token.FN_LATIN) && !p.peekTokenIs(token.TERM) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		field := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		if seen[field.Value] {
+			msg := fmt.Sprintf("duplicate field %s in dhancha %s", field.Value, stmt.Name.Value)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+		seen[field.Value] = true
+		stmt.Fields = append(stmt.Fields, field)
+
+		if p.peekTokenIs(token.FN_LATIN) || p.peekTokenIs(token.TERM) {
+			break
+		}
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	methods, ok := p.parseMethods("dhancha", stmt.Name.Value)
+	if !ok {
+		return nil
+	}
+	stmt.Methods = methods
+
+	if p.peekTokenIs(token.TERM) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseEnumStatement() *ast.EnumStatement {
+	stmt := &ast.EnumStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	names := []string{}
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		variant := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		if seen[variant.Value] {
+			msg := fmt.Sprintf("duplicate variant %s in ganana %s", variant.Value, stmt.Name.Value)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+		seen[variant.Value] = true
+		stmt.Variants = append(stmt.Variants, variant)
+		names = append(names, variant.Value)
+
+		p.skipLineBreaks()
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+	p.nextToken()
+
+	p.enums[stmt.Name.Value] = names
+
+	if p.peekTokenIs(token.TERM) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseClassStatement() *ast.ClassStatement {
+	stmt := &ast.ClassStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if p.peekTokenIs(token.LPAREN) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Parent = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		if !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	methods, ok := p.parseMethods("varg", stmt.Name.Value)
+	if !ok {
+		return nil
+	}
+	stmt.Methods = methods
+
 	if p.peekTokenIs(token.TERM) {
 		p.nextToken()
 	}
+
 	return stmt
 }
 
+// parses named karya methods up to and including the closing brace of the
+// dhancha or varg declaring them
+func (p *Parser) parseMethods(keyword, name string) ([]*ast.FunctionLiteral, bool) {
+	methods := []*ast.FunctionLiteral{}
+
+	seen := map[string]bool{}
+	for !p.peekTokenIs(token.RBRACE) && !p.peekTokenIs(token.EOF) {
+		p.nextToken()
+
+		switch p.curToken.Type {
+		case token.TERM, token.SINGLE_COMMENT, token.MULTI_COMMENT:
+			continue
+		case token.FN_LATIN:
+		default:
+			msg := fmt.Sprintf("expected a karya method in %s %s, got %s instead", keyword, name, p.curToken.Literal)
+			p.errors = append(p.errors, msg)
+			return nil, false
+		}
+
+		if !p.peekTokenIs(token.IDENT) {
+			msg := fmt.Sprintf("methods of %s %s need a name", keyword, name)
+			p.errors = append(p.errors, msg)
+			return nil, false
+		}
+
+		method, ok := p.parseFnLiteral().(*ast.FunctionLiteral)
+		if !ok {
+			return nil, false
+		}
+		if seen[method.Name.Value] {
+			msg := fmt.Sprintf("duplicate method %s in %s %s", method.Name.Value, keyword, name)
+			p.errors = append(p.errors, msg)
+			return nil, false
+		}
+		seen[method.Name.Value] = true
+		methods = append(methods, method)
+	}
+
+	return methods, p.expectPeek(token.RBRACE)
+}
+
 func (p *Parser) parsePrefixExpression() ast.Expression {
 	expression := &ast.PrefixExpression{
 		Token:    p.curToken,
@@ -315,6 +719,10 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	}
 
 	precedence := p.curPrecedence()
+	if expression.Token.Type == token.POWER {
+		// ** is right-associative: 2 ** 3 ** 2 == 2 ** (3 ** 2)
+		precedence--
+	}
 	p.nextToken()
 
 	expression.Right = p.parseExpression(precedence)
@@ -341,48 +749,267 @@ func (p *Parser) noPrefixParseFnError(t token.TokenType) {
 	p.errors = append(p.errors, msg)
 }
 
+// parses (x) as x, and (), (x,) and (x, y) as tuples
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	if !p.inPattern && p.arrowAhead() {
+		params := p.parseFunctionParameters()
+		if params == nil || !p.expectPeek(token.ARROW) {
+			return nil
+		}
+		return p.parseArrowFunction(params)
+	}
+
+	tuple := &ast.TupleLiteral{Token: p.curToken, Elements: []ast.Expression{}}
+
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return tuple
+	}
+
 	p.nextToken()
 
 	exp := p.parseExpression(LOWEST)
 
-	if !p.expectPeek(token.RPAREN) {
-		return nil
+	if !p.peekTokenIs(token.COMMA) {
+		if !p.expectPeek(token.RPAREN) {
+			return nil
+		}
+		return exp
 	}
 
-	return exp
-}
-
-func (p *Parser) parseIfExpression() ast.Expression {
-	expression := &ast.IfExpression{Token: p.curToken}
+	tuple.Elements = append(tuple.Elements, exp)
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if p.peekTokenIs(token.RPAREN) {
+			break
+		}
 
-	if !p.expectPeek(token.LPAREN) {
-		return nil
+		p.nextToken()
+		tuple.Elements = append(tuple.Elements, p.parseExpression(LOWEST))
 	}
-	p.nextToken()
 
-	expression.Condition = p.parseExpression(LOWEST)
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
-	if !p.expectPeek(token.LBRACE) {
-		return nil
-	}
-	expression.Consequence = p.parseBlockStatement()
 
-	if p.peekTokenIs(token.ELSE_LATIN) {
-		p.nextToken()
+	return tuple
+}
 
-		if !p.expectPeek(token.LBRACE) {
-			return nil
+// reports whether the parentheses opened at the current token close right
+// before a =>, making them the parameters of an arrow function. It scans ahead
+// on a copy of the lexer, so no tokens are consumed
+func (p *Parser) arrowAhead() bool {
+	l := *p.l
+	tok := p.peekToken
+
+	for depth := 1; ; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LPAREN, token.LBRACKET, token.LBRACE:
+			depth++
+		case token.RPAREN, token.RBRACKET, token.RBRACE:
+			depth--
+			if depth == 0 {
+				return l.NextToken().Type == token.ARROW
+			}
+		case token.EOF:
+			return false
 		}
-
-		expression.Alternative = p.parseBlockStatement()
 	}
+}
 
+// (x, y) => x + y or x => x * 2, once the parameters are parsed and the
+// current token is the =>; the body is a block or a single expression whose
+// value is returned
+func (p *Parser) parseArrowFunction(params []ast.Expression) ast.Expression {
+	lit := &ast.FunctionLiteral{Token: p.curToken, Parameters: params}
+
+	p.parseFunctionBody(lit, p.parseArrowBody)
+	if lit.Body == nil {
+		return nil
+	}
+
+	return lit
+}
+
+// parses what follows a =>: a block, or a single expression standing in for
+// a block of its own
+func (p *Parser) parseArrowBody() *ast.BlockStatement {
+	arrow := p.curToken
+
+	if p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		return p.parseBlockStatement()
+	}
+
+	p.nextToken()
+	body := &ast.ExpressionStatement{Token: p.curToken, Expression: p.parseExpression(LOWEST)}
+	if body.Expression == nil {
+		return nil
+	}
+
+	return &ast.BlockStatement{Token: arrow, Statements: []ast.Statement{body}}
+}
+
+// continues a tuple written without parentheses, as in labh a, b, once its
+// first element is parsed; anything not followed by a comma is left as it is
+func (p *Parser) parseBareTuple(first ast.Expression) ast.Expression {
+	if first == nil || !p.peekTokenIs(token.COMMA) {
+		return first
+	}
+
+	tuple := &ast.TupleLiteral{Token: p.peekToken, Elements: []ast.Expression{first}}
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+
+		el := p.parseExpression(LOWEST)
+		if el == nil {
+			return nil
+		}
+		tuple.Elements = append(tuple.Elements, el)
+	}
+
+	return tuple
+}
+
+func (p *Parser) parseIfExpression() ast.Expression {
+	expression := &ast.IfExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+
+	expression.Condition = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	expression.Consequence = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.ELSE_LATIN) {
+		p.nextToken()
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+
+		expression.Alternative = p.parseBlockStatement()
+	}
+
+	return expression
+}
+
+func (p *Parser) parseMatchExpression() ast.Expression {
+	expression := &ast.MatchExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+
+	expression.Subject = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) && !p.peekTokenIs(token.EOF) {
+		p.nextToken()
+
+		switch p.curToken.Type {
+		case token.COMMA, token.TERM, token.SINGLE_COMMENT, token.MULTI_COMMENT:
+			continue
+		}
+
+		arm := p.parseMatchArm()
+		if arm == nil {
+			return nil
+		}
+		expression.Arms = append(expression.Arms, arm)
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	p.matches = append(p.matches, expression)
 	return expression
 }
 
+// parses pattern => body, where the body is a block or a single expression
+// and the pattern is _ or an expression the subject must equal
+func (p *Parser) parseMatchArm() *ast.MatchArm {
+	arm := &ast.MatchArm{}
+
+	if !p.curTokenIs(token.IDENT) || p.curToken.Literal != "_" {
+		inPattern := p.inPattern
+		p.inPattern = true
+		arm.Pattern = p.parseExpression(LOWEST)
+		p.inPattern = inPattern
+
+		if arm.Pattern == nil {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.ARROW) {
+		return nil
+	}
+	arm.Token = p.curToken
+
+	arm.Body = p.parseArrowBody()
+	if arm.Body == nil {
+		return nil
+	}
+
+	return arm
+}
+
+// finds the variants of a ganana that a milao over it leaves unhandled. A
+// milao is over a ganana when every pattern is one of its variants, written
+// as Rang.Lal; a _ arm handles whatever is left
+func (p *Parser) missingVariants(match *ast.MatchExpression) ([]string, string, bool) {
+	enum := ""
+	handled := map[string]bool{}
+
+	for _, arm := range match.Arms {
+		if arm.Pattern == nil {
+			return nil, "", false
+		}
+
+		member, ok := arm.Pattern.(*ast.MemberExpression)
+		if !ok {
+			return nil, "", false
+		}
+		name, ok := member.Object.(*ast.Identifier)
+		if !ok || enum != "" && name.Value != enum {
+			return nil, "", false
+		}
+
+		enum = name.Value
+		handled[member.Property.Value] = true
+	}
+
+	variants, ok := p.enums[enum]
+	if !ok {
+		return nil, "", false
+	}
+
+	missing := []string{}
+	for _, variant := range variants {
+		if !handled[variant] {
+			missing = append(missing, variant)
+		}
+	}
+
+	return missing, enum, len(missing) > 0
+}
+
 func (p *Parser) parseWhileExpression() ast.Expression {
 	expression := &ast.WhileExpression{Token: p.curToken}
 
@@ -404,9 +1031,113 @@ func (p *Parser) parseWhileExpression() ast.Expression {
 	return expression
 }
 
+func (p *Parser) parseForEachExpression() ast.Expression {
+	expression := &ast.ForEachExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+	p.nextToken()
+
+	expression.Variable = p.parseExpression(LESSGREATER)
+	if expression.Variable == nil {
+		return nil
+	}
+	if !p.checkPattern(expression.Variable) {
+		msg := fmt.Sprintf("invalid loop variable: %s", expression.Variable.String())
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
+	if !p.expectPeek(token.IN_LATIN) {
+		return nil
+	}
+	p.nextToken()
+
+	expression.Iterable = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Body = p.parseBlockStatement()
+
+	return expression
+}
+
+func (p *Parser) parseTryExpression() ast.Expression {
+	expression := &ast.TryExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	expression.Block = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.CATCH_LATIN) {
+		p.nextToken()
+
+		if p.peekTokenIs(token.LPAREN) {
+			p.nextToken()
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			expression.CatchParam = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			if !p.expectPeek(token.RPAREN) {
+				return nil
+			}
+		}
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+		expression.Catch = p.parseBlockStatement()
+	}
+
+	if p.peekTokenIs(token.FINALLY_LATIN) {
+		p.nextToken()
+
+		if !p.expectPeek(token.LBRACE) {
+			return nil
+		}
+		expression.Finally = p.parseBlockStatement()
+	}
+
+	if expression.Catch == nil && expression.Finally == nil {
+		p.errors = append(p.errors, "koshish needs a pakdo or aakhir block")
+		return nil
+	}
+
+	return expression
+}
+
+func (p *Parser) parseFunctionStatement() *ast.FunctionStatement {
+	stmt := &ast.FunctionStatement{Token: p.curToken}
+
+	lit, ok := p.parseFnLiteral().(*ast.FunctionLiteral)
+	if !ok {
+		return nil
+	}
+
+	stmt.Name = lit.Name
+	stmt.Function = lit
+
+	if p.peekTokenIs(token.TERM) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseFnLiteral() ast.Expression {
 	lit := &ast.FunctionLiteral{Token: p.curToken}
 
+	if p.peekTokenIs(token.IDENT) {
+		p.nextToken()
+		lit.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
 	if !p.expectPeek(token.LPAREN) {
 		return nil
 	}
@@ -417,35 +1148,178 @@ func (p *Parser) parseFnLiteral() ast.Expression {
 		return nil
 	}
 
-	lit.Body = p.parseBlockStatement()
+	p.parseFunctionBody(lit, p.parseBlockStatement)
 
 	return lit
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
-	identifiers := []*ast.Identifier{}
+// parses the body of lit with parse, making lit a generator if the body has
+// an upaj outside the functions nested in it
+func (p *Parser) parseFunctionBody(lit *ast.FunctionLiteral, parse func() *ast.BlockStatement) {
+	outer := p.yields
+	yields := false
+	p.yields = &yields
+
+	lit.Body = parse()
+	lit.Generator = yields
+
+	p.yields = outer
+}
+
+func (p *Parser) parseFunctionParameters() []ast.Expression {
+	params := []ast.Expression{}
 
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return identifiers
+		return params
 	}
 
 	p.nextToken()
 
-	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	identifiers = append(identifiers, ident)
+	param := p.parseParameter()
+	if param == nil {
+		return nil
+	}
+	params = append(params, param)
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		p.nextToken()
 
-		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-		identifiers = append(identifiers, ident)
+		param := p.parseParameter()
+		if param == nil {
+			return nil
+		}
+		params = append(params, param)
 	}
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
-	return identifiers
+	if !p.checkParameterOrder(params) {
+		return nil
+	}
+	return params
+}
+
+// parses a single function parameter: a name or destructuring pattern with an
+// optional default value, or a ...rest parameter
+func (p *Parser) parseParameter() ast.Expression {
+	var param ast.Expression
+
+	switch p.curToken.Type {
+	case token.IDENT:
+		param = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	case token.LBRACKET, token.LBRACE:
+		param = p.parsePattern()
+		if param == nil {
+			return nil
+		}
+	case token.ELLIPSIS:
+		rest := &ast.SpreadExpression{Token: p.curToken}
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		rest.Value = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		return rest
+	default:
+		msg := fmt.Sprintf("expected parameter name or pattern, got %s", p.curToken.Type)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
+	if p.peekTokenIs(token.ASSIGN) {
+		p.nextToken()
+		def := &ast.DefaultParameter{Token: p.curToken, Name: param}
+
+		p.nextToken()
+		def.Default = p.parseExpression(LOWEST)
+		return def
+	}
+
+	return param
+}
+
+// a ...rest parameter can only come last, and once a parameter has a default
+// value every parameter after it needs one too
+func (p *Parser) checkParameterOrder(params []ast.Expression) bool {
+	seenDefault := false
+
+	for i, param := range params {
+		switch param := param.(type) {
+		case *ast.SpreadExpression:
+			if i != len(params)-1 {
+				msg := fmt.Sprintf("rest parameter %s must be the last parameter", param.String())
+				p.errors = append(p.errors, msg)
+				return false
+			}
+		case *ast.DefaultParameter:
+			seenDefault = true
+		default:
+			if seenDefault {
+				msg := fmt.Sprintf("parameter %s without a default value follows a parameter with one", param.String())
+				p.errors = append(p.errors, msg)
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// parses an array or hash literal and checks that it can be used as a destructuring pattern
+func (p *Parser) parsePattern() ast.Expression {
+	errors := len(p.errors)
+	pattern := p.parseExpression(LOWEST)
+	// a pattern that failed to parse may have nil parts, already reported
+	if pattern == nil || len(p.errors) > errors {
+		return nil
+	}
+
+	if !p.checkPattern(pattern) {
+		msg := fmt.Sprintf("invalid destructuring pattern: %s", pattern.String())
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
+	return pattern
+}
+
+// a pattern is an identifier, an array of patterns optionally ending in ...rest,
+// a tuple of patterns, or a hash whose values are patterns
+func (p *Parser) checkPattern(pattern ast.Expression) bool {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		return true
+	case *ast.ArrayLiteral:
+		for i, el := range pattern.Elements {
+			if spread, ok := el.(*ast.SpreadExpression); ok {
+				if _, ok := spread.Value.(*ast.Identifier); !ok || i != len(pattern.Elements)-1 {
+					return false
+				}
+				continue
+			}
+			if !p.checkPattern(el) {
+				return false
+			}
+		}
+		return true
+	case *ast.TupleLiteral:
+		for _, el := range pattern.Elements {
+			if !p.checkPattern(el) {
+				return false
+			}
+		}
+		return true
+	case *ast.HashLiteral:
+		for _, pair := range pattern.Pairs {
+			if !p.checkPattern(pair.Value) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
 }
 
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
@@ -472,6 +1346,25 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	return exp
 }
 
+// x |> f(y) is sugar for f(x, y), and x |> f for f(x), so a pipeline like
+// arr |> filter(even) |> jod becomes nested calls
+func (p *Parser) parsePipeExpression(left ast.Expression) ast.Expression {
+	pipe := p.curToken
+	p.nextToken()
+
+	right := p.parseExpression(PIPE)
+	if right == nil {
+		return nil
+	}
+
+	if call, ok := right.(*ast.CallExpression); ok {
+		call.Arguments = append([]ast.Expression{left}, call.Arguments...)
+		return call
+	}
+
+	return &ast.CallExpression{Token: pipe, Function: right, Arguments: []ast.Expression{left}}
+}
+
 func (p *Parser) parseCallArguments() []ast.Expression {
 	args := []ast.Expression{}
 
@@ -496,24 +1389,49 @@ func (p *Parser) parseCallArguments() []ast.Expression {
 	return args
 }
 
+func (p *Parser) parseSpreadExpression() ast.Expression {
+	expression := &ast.SpreadExpression{Token: p.curToken}
+
+	p.nextToken()
+	expression.Value = p.parseExpression(PREFIX)
+
+	return expression
+}
+
 func (p *Parser) parseArrayLiteral() ast.Expression {
 	arr := &ast.ArrayLiteral{Token: p.curToken}
 
-	arr.Elements = p.parseExpressionList(token.RBRACKET)
+	if p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		arr.Elements = []ast.Expression{}
+		return arr
+	}
+
+	p.nextToken()
+	first := p.parseExpression(LOWEST)
+	if first != nil && p.peekTokenIs(token.FOR_LATIN) {
+		return p.parseComprehension(arr.Token, nil, first, token.RBRACKET)
+	}
+
+	arr.Elements = p.parseRestOfList(first, token.RBRACKET)
 
 	return arr
 }
 
 func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
-	list := []ast.Expression{}
-
 	if p.peekTokenIs(end) {
 		p.nextToken()
-		return list
+		return []ast.Expression{}
 	}
 
 	p.nextToken()
-	list = append(list, p.parseExpression(LOWEST))
+
+	return p.parseRestOfList(p.parseExpression(LOWEST), end)
+}
+
+// finishes a comma separated list once its first element is parsed
+func (p *Parser) parseRestOfList(first ast.Expression, end token.TokenType) []ast.Expression {
+	list := []ast.Expression{first}
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
@@ -528,37 +1446,95 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	return list
 }
 
+func (p *Parser) parseMemberExpression(left ast.Expression) ast.Expression {
+	exp := &ast.MemberExpression{Token: p.curToken, Object: left}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	exp.Property = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	return exp
+}
+
 func (p *Parser) parseIndexSliceExpression(left ast.Expression) ast.Expression {
-	var exp ast.Expression
 	tmp := p.curToken
-
 	p.nextToken()
-	inBracket := p.parseExpression(LOWEST)
 
-	if slice, ok := inBracket.(*ast.SliceExpression); ok {
-		exp = &ast.SliceArrayExpression{Token: slice.Token, Left: left, Slice: *slice}
-	} else {
-		exp = &ast.IndexExpression{Token: tmp, Left: left, Index: inBracket}
+	var start ast.Expression
+	if !p.curTokenIs(token.COLON) {
+		start = p.parseExpression(SLICE)
+		if !p.peekTokenIs(token.COLON) {
+			if !p.expectPeek(token.RBRACKET) {
+				return nil
+			}
+			return &ast.IndexExpression{Token: tmp, Left: left, Index: start}
+		}
+		p.nextToken()
+	}
+
+	slice := ast.SliceExpression{Token: p.curToken, Left: start}
+	slice.Right = p.parseSliceBound()
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		slice.Step = p.parseSliceBound()
 	}
 
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
 	}
 
-	return exp
+	return &ast.SliceArrayExpression{Token: slice.Token, Left: left, Slice: slice}
+}
+
+// parses the bound following a ':' inside brackets, or returns nil when it's left open
+func (p *Parser) parseSliceBound() ast.Expression {
+	if p.peekTokenIs(token.COLON) || p.peekTokenIs(token.RBRACKET) {
+		return nil
+	}
+	p.nextToken()
+
+	return p.parseExpression(SLICE)
 }
 
 func (p *Parser) parseHashLiteral() ast.Expression {
 	hash := &ast.HashLiteral{Token: p.curToken}
-	hash.Pairs = make(map[ast.Expression]ast.Expression)
+
+	// braces holding bare elements rather than key: value pairs make a set
+	set := &ast.SetLiteral{Token: p.curToken}
 
 	for !p.peekTokenIs(token.RBRACE) {
 		p.nextToken()
 
-		slice, ok := p.parseExpression(LOWEST).(*ast.SliceExpression)
-		if !ok {
+		el := p.parseExpression(LOWEST)
+		if el == nil {
 			return nil
 		}
+
+		slice, ok := el.(*ast.SliceExpression)
+		if len(hash.Pairs) == 0 && len(set.Elements) == 0 {
+			p.skipLineBreaks()
+			if p.peekTokenIs(token.FOR_LATIN) {
+				if ok {
+					return p.parseComprehension(hash.Token, slice.Left, slice.Right, token.RBRACE)
+				}
+				return p.parseComprehension(hash.Token, nil, el, token.RBRACE)
+			}
+		}
+
+		if !ok || len(set.Elements) > 0 {
+			if len(hash.Pairs) > 0 || ok {
+				p.errors = append(p.errors, "cannot mix hash pairs and set elements in one literal")
+				return nil
+			}
+
+			set.Elements = append(set.Elements, el)
+			p.skipLineBreaks()
+			if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+				return nil
+			}
+			continue
+		}
 		// if !p.expectPeek(token.COLON) {
 		// 	fmt.Println(p.curToken, p.peekToken, key)
 		// 	return nil
@@ -566,8 +1542,9 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 
 		// p.nextToken()
 		// value := p.parseExpression(LOWEST)
-		hash.Pairs[slice.Left] = slice.Right
+		hash.Pairs = append(hash.Pairs, ast.HashPair{Key: slice.Left, Value: slice.Right})
 
+		p.skipLineBreaks()
 		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
 			return nil
 		}
@@ -577,13 +1554,72 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 		return nil
 	}
 
+	if len(set.Elements) > 0 {
+		return set
+	}
 	return hash
 }
 
+// parses the har and agar clauses of a comprehension, and its closing end,
+// once its element is parsed and a har is up next
+func (p *Parser) parseComprehension(tok token.Token, key, value ast.Expression, end token.TokenType) ast.Expression {
+	comp := &ast.Comprehension{Token: tok, Key: key, Value: value}
+
+	for p.skipLineBreaks(); p.peekTokenIs(token.FOR_LATIN) || p.peekTokenIs(token.IF_LATIN); p.skipLineBreaks() {
+		p.nextToken()
+		clause := &ast.ComprehensionClause{Token: p.curToken}
+		p.nextToken()
+
+		if clause.Token.Type == token.IF_LATIN {
+			clause.Condition = p.parseExpression(LOWEST)
+			if clause.Condition == nil {
+				return nil
+			}
+			comp.Clauses = append(comp.Clauses, clause)
+			continue
+		}
+
+		clause.Variable = p.parseExpression(LESSGREATER)
+		if clause.Variable == nil {
+			return nil
+		}
+		if !p.checkPattern(clause.Variable) {
+			msg := fmt.Sprintf("invalid loop variable: %s", clause.Variable.String())
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+
+		if !p.expectPeek(token.IN_LATIN) {
+			return nil
+		}
+		p.nextToken()
+
+		clause.Iterable = p.parseExpression(LOWEST)
+		if clause.Iterable == nil {
+			return nil
+		}
+		comp.Clauses = append(comp.Clauses, clause)
+	}
+
+	if !p.expectPeek(end) {
+		return nil
+	}
+
+	return comp
+}
+
 func (p *Parser) parseComment() ast.Expression {
 	return &ast.Comment{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+// skips the terminators the lexer puts at line breaks, for braces holding a
+// list, like a hash literal or a ganana, where the last entry may end a line
+func (p *Parser) skipLineBreaks() {
+	for p.peekTokenIs(token.TERM) && p.peekToken.Literal == "\n" {
+		p.nextToken()
+	}
+}
+
 func (p *Parser) curTokenIs(t token.TokenType) bool {
 	return p.curToken.Type == t
 }
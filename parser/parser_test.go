@@ -485,6 +485,70 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a * b[2], b[1], 2 * [1, 2][1])",
 			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
 		},
+		{
+			"2 ** 3 ** 2",
+			"(2 ** (3 ** 2))",
+		},
+		{
+			"a * b ** c",
+			"(a * (b ** c))",
+		},
+		{
+			"-2 ** 2",
+			"(-(2 ** 2))",
+		},
+		{
+			"2 ** -1",
+			"(2 ** (-1))",
+		},
+		{
+			"a // b * c",
+			"((a // b) * c)",
+		},
+		{
+			"a + b << c",
+			"((a + b) << c)",
+		},
+		{
+			"a & b ^ c & d",
+			"((a & b) ^ (c & d))",
+		},
+		{
+			"a << 1 & b",
+			"((a << 1) & b)",
+		},
+		{
+			"a ^ b == c",
+			"((a ^ b) == c)",
+		},
+		{
+			"~a & b",
+			"((~a) & b)",
+		},
+		{
+			"1..n + 1",
+			"(1 .. (n + 1))",
+		},
+		{
+			"a..<b & c",
+			"(a ..< (b & c))",
+		},
+		{
+			"a[1..3]",
+			"(a[(1 .. 3)])",
+		},
+		{
+			"x mein xs == satya",
+			"((x mein xs) == satya)",
+		},
+		{
+			"a + 1 mein 1..n",
+			"((a + 1) mein (1 .. n))",
+		},
+		{
+			"!(x में xs)",
+			"(!(x mein xs))",
+		},
 	}
 
 	for _, tt := range tests {
@@ -501,6 +565,40 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 	}
 }
 
+func TestCompoundAssignmentParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		operator string
+	}{
+		{"x += 1|", "+="},
+		{"x %= 1|", "%="},
+		{"x **= 1|", "**="},
+		{"x //= 1|", "//="},
+		{"x &= 1|", "&="},
+		{"x ^= 1|", "^="},
+		{"x <<= 1|", "<<="},
+		{"x >>= 1|", ">>="},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.CompoundAssignmentStatement)
+		if !ok {
+			t.Fatalf("stmt not *ast.CompoundAssignmentStatement. got=%T", program.Statements[0])
+		}
+		if stmt.Operator != tt.operator {
+			t.Errorf("stmt.Operator is not %q. got=%q", tt.operator, stmt.Operator)
+		}
+		testIdentifier(t, stmt.Name, "x")
+		testIntegerLiteral(t, stmt.Value, 1)
+	}
+}
+
 func TestBooleanExpression(t *testing.T) {
 	tests := []struct {
 		input           string
@@ -923,14 +1021,406 @@ func TestParsingHashLiteralsStringKeys(t *testing.T) {
 		"do":   2,
 		"teen": 3,
 	}
-	for key, value := range hash.Pairs {
-		literal, ok := key.(*ast.StringLiteral)
+	for _, pair := range hash.Pairs {
+		literal, ok := pair.Key.(*ast.StringLiteral)
 		if !ok {
-			t.Errorf("key is not ast.StringLiteral. got=%T", key)
+			t.Errorf("key is not ast.StringLiteral. got=%T", pair.Key)
 		}
 
 		expectedValue := expected[literal.String()]
-		testIntegerLiteral(t, value, expectedValue)
+		testIntegerLiteral(t, pair.Value, expectedValue)
+	}
+}
+
+func TestHashLiteralKeyOrder(t *testing.T) {
+	input := `{"teen": 3, "ek": 1, "do": 2, 10: "das"}`
+
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	expected := []string{"teen", "ek", "do", "10"}
+	if len(hash.Pairs) != len(expected) {
+		t.Fatalf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+	for i, pair := range hash.Pairs {
+		if pair.Key.String() != expected[i] {
+			t.Errorf("hash.Pairs[%d].Key wrong. expected=%q, got=%q", i, expected[i], pair.Key.String())
+		}
+	}
+
+	if hash.String() != "{teen:3, ek:1, do:2, 10:das}" {
+		t.Errorf("hash.String() wrong. got=%q", hash.String())
+	}
+}
+
+func TestSetLiteralParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"{1, 2, 3}", "{1, 2, 3}"},
+		{"{a + 1, [1, 2],}", "{(a + 1), [1, 2]}"},
+		{"{1: 2}", "{1:2}"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	for _, input := range []string{"{1, 2: 3}", "{1: 2, 3}"} {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+		}
+	}
+}
+
+func TestComprehensionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[x * x har x mein xs]", "[(x * x) har x mein xs]"},
+		{"[x har x mein xs agar x % 2 == 0]", "[x har x mein xs agar ((x % 2) == 0)]"},
+		{"[(a, b) har a mein xs har b mein ys agar a < b]", "[(a, b) har a mein xs har b mein ys agar (a < b)]"},
+		{"[a har [a, b] mein pairs]", "[a har [a, b] mein pairs]"},
+		{"{x har x mein xs}", "{x har x mein xs}"},
+		{"{k: v * 2 har k mein xs agar k}", "{k: (v * 2) har k mein xs agar k}"},
+		{"{\nk: 1\nhar k mein xs\nagar k\n}", "{k: 1 har k mein xs agar k}"},
+		{"[x har x mein 1..3]", "[x har x mein (1 .. 3)]"},
+		{"[x, y]", "[x, y]"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	inputs := []string{
+		"[x har 1 mein xs]",
+		"[x har x xs]",
+		"[x har x mein xs, y]",
+		"[x, y har y mein xs]",
+		"{1: 2, x har x mein xs}",
+		"[x har x mein xs agar]",
+	}
+	for _, input := range inputs {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+		}
+	}
+}
+
+func TestYieldStatementParsing(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  string
+		generator bool
+	}{
+		{"karya () { upaj 1| }", "karya() upaj 1|", true},
+		{"karya () { agar (x) { upaj x + 1 } }", "karya() agar x { upaj (x + 1)| } ", true},
+		{"karya () { labh 1| }", "karya() labh 1|", false},
+		{"() => { upaj 1 }", "() => upaj 1|", true},
+		{"karya () { karya () { upaj 1| } }", "karya() karya() upaj 1|", false},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		fn, ok := stmt.Expression.(*ast.FunctionLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.FunctionLiteral. got=%T", stmt.Expression)
+		}
+		if fn.Generator != tt.generator {
+			t.Errorf("fn.Generator for %q not %t. got=%t", tt.input, tt.generator, fn.Generator)
+		}
+	}
+
+	for _, input := range []string{"upaj 1", "agar (x) { upaj 1 }", "karya () { upaj }"} {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+		}
+	}
+}
+
+func TestTupleParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"(1, 2)", "(1, 2)"},
+		{"(a + 1,)", "((a + 1),)"},
+		{"()", "()"},
+		{"(1)", "1"},
+		{"((1, 2), 3)", "((1, 2), 3)"},
+		{"karya() { labh a, b| }", "karya() labh (a, b)|"},
+		{"mana q, r = x", "mana (q, r) = x|"},
+		{"mana (q, r) = 1, 2", "mana (q, r) = (1, 2)|"},
+		{"q = 1, 2", "mana q = (1, 2)|"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	for _, input := range []string{"mana q, 1 = x", "(1, 2", "(, 1)"} {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+		}
+	}
+}
+
+// a line break ends a statement, as a | would, when the line's last token
+// could end one, the line isn't inside ( ) or [ ], and the next line doesn't
+// carry the statement on
+func TestAutomaticTerminators(t *testing.T) {
+	tests := []struct {
+		input      string
+		statements []string
+	}{
+		// names, literals, satya, asatya, yeh and closing brackets end a line
+		{"mana x = 5\n-1", []string{"mana x = 5|", "(-1)"}},
+		{"f(x)\n(1, 2)", []string{"f(x)", "(1, 2)"}},
+		{"mana a = b\n[1, 2]", []string{"mana a = b|", "[1, 2]"}},
+		{"satya\nasatya\n'a'\n\"b\"\n1.5d", []string{"satya", "asatya", "'a'", "b", "1.5d"}},
+		{"mana f = karya() { 1 }\nf()", []string{"mana f = karya() 1|", "f()"}},
+		// an operator or comma at the end of a line carries on to the next
+		{"mana y = 1 +\n2", []string{"mana y = (1 + 2)|"}},
+		{"mana q, r =\n1, 2", []string{"mana (q, r) = (1, 2)|"}},
+		// nothing ends inside ( ) or [ ]
+		{"f(1,\n2\n)", []string{"f(1, 2)"}},
+		{"mana a = [\n1,\n2\n]", []string{"mana a = [1, 2]|"}},
+		{"mana y = (1\n+ 2)", []string{"mana y = (1 + 2)|"}},
+		// but statements inside { } do end, even inside ( )
+		{"f(karya() {\na\nb\n})", []string{"f(karya() ab)"}},
+		// a line starting with ., |>, =>, {, varna, pakdo or aakhir carries on
+		{"arr\n.lambai()", []string{"(arr.lambai)()"}},
+		{"arr\n|> f", []string{"f(arr)"}},
+		{"agar (x)\n{ 1 }\nvarna\n{ 2 }", []string{"agar x { 1 } varna  { 2 } "}},
+		{"koshish { a }\npakdo (e) { b }\naakhir { c }", []string{"koshish { a } pakdo (e) { b } aakhir { c } "}},
+		{"milao (x) {\n1 => 2\n_ => 3\n}", []string{"milao (x) { 1 => 2, _ => 3 }"}},
+		// comments and blank lines don't matter
+		{"a # ek\n\n# do\nb", []string{"a", "# ek", "# do", "b"}},
+		{"a\n\n\n.b", []string{"(a.b)"}},
+		{"arr # ek\n.lambai()", []string{"(arr.lambai)()"}},
+		{"arr\n/* ek */\n.lambai()", []string{"(arr.lambai)()"}},
+		{"arr # ek\n|> f", []string{"f(arr)"}},
+		{"agar (x) { 1 } # ek\nvarna { 2 }", []string{"agar x { 1 } varna  { 2 } "}},
+		{"mana y = 1 + # ek\n2", []string{"mana y = (1 + 2)|"}},
+		{"1 + /* ek */ 2", []string{"(1 + 2)"}},
+		{"{ # ek\n\"a\": 1 }", []string{"{a:1}"}},
+		{"f(1, # ek\n2)", []string{"f(1, 2)"}},
+		// but a comment on a line of its own ends there, whatever follows
+		{"mana t = 1\n# ek\n(1, 2) |> f", []string{"mana t = 1|", "# ek", "f((1, 2))"}},
+		{"mana a = [1]\n# ek\n[5][0]", []string{"mana a = [1]|", "# ek", "([5][0])"}},
+		{"f(1)\n/* ek */\n-1", []string{"f(1)", "/* ek */", "(-1)"}},
+		{"# ek\n-1", []string{"# ek", "(-1)"}},
+		// an explicit | still works, and a line break after it adds nothing
+		{"a|\nb| c", []string{"a", "b", "c"}},
+		// braces holding a list may end their last line without a comma
+		{"{\n\"a\": 1,\n\"b\": 2\n}", []string{"{a:1, b:2}"}},
+		{"{\n1,\n2\n}", []string{"{1, 2}"}},
+		{"ganana Rang {\nLal,\nHara\n}", []string{"ganana Rang { Lal, Hara }"}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		statements := []string{}
+		for _, stmt := range program.Statements {
+			statements = append(statements, stmt.String())
+		}
+		if fmt.Sprintf("%q", statements) != fmt.Sprintf("%q", tt.statements) {
+			t.Errorf("wrong statements for %q. expected=%q, got=%q", tt.input, tt.statements, statements)
+		}
+	}
+
+	// two statements on a line, or a list entry missing its comma, still need
+	// a separator
+	for _, input := range []string{"{\n1: 2\n3: 4\n}", "ganana Rang {\nLal\nHara\n}"} {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+		}
+	}
+}
+
+func TestPipeExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x |> f", "f(x)"},
+		{"x |> f(y)", "f(x, y)"},
+		{"arr |> filter(even) |> map(double) |> jod", "jod(map(filter(arr, even), double))"},
+		{"a + b |> f", "f((a + b))"},
+		{"1..10 |> f", "f((1 .. 10))"},
+		{"x |> f == 3", "(f(x) == 3)"},
+		{"x |> obj.method(1)", "(obj.method)(x, 1)"},
+		{"x |> ((n) => n * 2)", "(n) => (n * 2)(x)"},
+		{"mana y = x |> f| y", "mana y = f(x)|y"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	for _, input := range []string{"x |>", "|> f"} {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+		}
+	}
+}
+
+func TestArrowFunctionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"(x) => x * 2", "(x) => (x * 2)"},
+		{"x => x * 2", "(x) => (x * 2)"},
+		{"() => 1", "() => 1"},
+		{"(a, b = 2, ...rest) => a", "(a, b = 2, ...rest) => a"},
+		{"([a, b]) => a + b", "([a, b]) => (a + b)"},
+		{"(x) => { mana y = x| y }", "(x) => mana y = x|y"},
+		{"map(xs, (x) => x + 1)", "map(xs, (x) => (x + 1))"},
+		{"x => y => x + y", "(x) => (y) => (x + y)"},
+		{"(x + 1) * 2", "((x + 1) * 2)"},
+		{"(a, b)", "(a, b)"},
+		{"milao (t) { (1, 2) => 3, x => x }", "milao (t) { (1, 2) => 3, x => x }"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	for _, input := range []string{"(1) => 2", "(a, b) =>", "(a + b) => a"} {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+		}
+	}
+}
+
+func TestCharLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected rune
+	}{
+		{"'a'", 'a'},
+		{"'क'", 'क'},
+		{"'ि'", 'ि'},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.CharLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.CharLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %q. got=%q", tt.expected, literal.Value)
+		}
+	}
+
+	for _, input := range []string{"''", "'ab'", "'कि'"} {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+		}
 	}
 }
 
@@ -983,10 +1473,10 @@ func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 		},
 	}
 
-	for key, value := range hash.Pairs {
-		literal, ok := key.(*ast.StringLiteral)
+	for _, pair := range hash.Pairs {
+		literal, ok := pair.Key.(*ast.StringLiteral)
 		if !ok {
-			t.Errorf("key is not ast.StringLiteral. got=%T", key)
+			t.Errorf("key is not ast.StringLiteral. got=%T", pair.Key)
 			continue
 		}
 
@@ -995,6 +1485,537 @@ func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 			t.Errorf("No test function for key %q found", literal.String())
 			continue
 		}
-		testFunc(value)
+		testFunc(pair.Value)
+	}
+}
+
+func TestDestructuringLetStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"mana [a, b] = arr|", "mana [a, b] = arr|"},
+		{"mana [a, b, ...rest] = arr|", "mana [a, b, ...rest] = arr|"},
+		{"mana [a, [b, c]] = arr|", "mana [a, [b, c]] = arr|"},
+		{`mana {"naam": n} = h|`, `mana {naam:n} = h|`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d",
+				len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("stmt not *ast.LetStatement. got=%T", program.Statements[0])
+		}
+		if stmt.Pattern == nil {
+			t.Fatalf("stmt.Pattern is nil")
+		}
+		if stmt.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, stmt.String())
+		}
+	}
+}
+
+func TestInvalidDestructuringPatterns(t *testing.T) {
+	tests := []string{
+		"mana [a, 1] = arr|",
+		"mana [...rest, a] = arr|",
+		`mana {"naam": "n"} = h|`,
+		"karya([a, 2]) { a }|",
+		"mana($%0",
+		"mana [a, -] = arr|",
+		"karya([a + ) { a }|",
+	}
+
+	for _, input := range tests {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+		}
+	}
+}
+
+func TestFunctionPatternParameters(t *testing.T) {
+	input := `karya([a, b], {"naam": n}, c) { a }|`
+
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	function := stmt.Expression.(*ast.FunctionLiteral)
+
+	if len(function.Parameters) != 3 {
+		t.Fatalf("length parameters wrong. want 3, got=%d\n", len(function.Parameters))
+	}
+	if _, ok := function.Parameters[0].(*ast.ArrayLiteral); !ok {
+		t.Errorf("parameter 0 is not *ast.ArrayLiteral. got=%T", function.Parameters[0])
+	}
+	if _, ok := function.Parameters[1].(*ast.HashLiteral); !ok {
+		t.Errorf("parameter 1 is not *ast.HashLiteral. got=%T", function.Parameters[1])
+	}
+	testLiteralExpression(t, function.Parameters[2], "c")
+}
+
+func TestDefaultAndRestParameterParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"karya(x, y = 10) { x }|", "karya(x, y = 10) x"},
+		{"karya(x = 1 + 2) { x }|", "karya(x = (1 + 2)) x"},
+		{"karya(first, ...rest) { first }|", "karya(first, ...rest) first"},
+		{"karya(x, y = 2, ...rest) { x }|", "karya(x, y = 2, ...rest) x"},
+		{"karya([a, b] = [1, 2]) { a }|", "karya([a, b] = [1, 2]) a"},
+		{"jod(...nums)|", "jod(...nums)"},
+		{"jod(1, ...nums, 2)|", "jod(1, ...nums, 2)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestInvalidParameterOrder(t *testing.T) {
+	tests := []string{
+		"karya(...rest, x) { x }|",
+		"karya(x = 1, y) { x }|",
+		"karya(...1) { 1 }|",
+	}
+
+	for _, input := range tests {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+		}
+	}
+}
+
+func TestFunctionStatementParsing(t *testing.T) {
+	input := `karya jod(x, y) { x + y | }
+karya ghatao(x, y) { x - y | }|`
+
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d",
+			len(program.Statements))
+	}
+
+	tests := []string{"jod", "ghatao"}
+	for i, name := range tests {
+		stmt, ok := program.Statements[i].(*ast.FunctionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[%d] is not ast.FunctionStatement. got=%T",
+				i, program.Statements[i])
+		}
+		if !testIdentifier(t, stmt.Name, name) {
+			return
+		}
+		if len(stmt.Function.Parameters) != 2 {
+			t.Fatalf("function literal parameters wrong. want 2, got=%d\n",
+				len(stmt.Function.Parameters))
+		}
+	}
+}
+
+func TestNamedFunctionLiteralParsing(t *testing.T) {
+	input := `mana f = karya fact(n) { n | }|`
+
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.LetStatement)
+	function, ok := stmt.Value.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("stmt.Value is not ast.FunctionLiteral. got=%T", stmt.Value)
+	}
+	if !testIdentifier(t, function.Name, "fact") {
+		return
+	}
+	if function.String() != "karya fact(n) n" {
+		t.Errorf("function.String() wrong. got=%q", function.String())
+	}
+}
+
+func TestTryExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			`koshish { x } pakdo (e) { y }`,
+			"koshish { x } pakdo (e) { y } ",
+		},
+		{
+			`koshish { x } pakdo { y }`,
+			"koshish { x } pakdo { y } ",
+		},
+		{
+			`koshish { x } aakhir { z }`,
+			"koshish { x } aakhir { z } ",
+		},
+		{
+			`कोशिश { x } पकड़ो (e) { y } आखिर { z }`,
+			"koshish { x } pakdo (e) { y } aakhir { z } ",
+		},
+		{
+			`phenko "galat"|`,
+			`phenko galat|`,
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d",
+				len(program.Statements))
+		}
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestTryWithoutHandlers(t *testing.T) {
+	l := lexer.New(`koshish { x }`)
+	p := New(l)
+	p.ParseProgram()
+
+	if len(p.Errors()) == 0 {
+		t.Errorf("expected parser errors for koshish without pakdo or aakhir, got none")
+	}
+}
+
+func TestSliceExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a[1:2]", "(a[(1 : 2)])"},
+		{"a[:3]", "(a[(: 3)])"},
+		{"a[2:]", "(a[(2 :)])"},
+		{"a[-2:]", "(a[((-2) :)])"},
+		{"a[::2]", "(a[(: : 2)])"},
+		{"a[1:n - 1:-1]", "(a[(1 : (n - 1) : (-1))])"},
+		{"a[:]", "(a[(:)])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestForEachExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"har (x mein xs) { x }", "har x mein xs { x } "},
+		{"har (i mein 1..<n) { i }", "har i mein (1 ..< n) { i } "},
+		{"har (x mein y mein z) { x }", "har x mein (y mein z) { x } "},
+		{"har ([k, v] mein pairs) { k }", "har [k, v] mein pairs { k } "},
+		{"हर (x में xs) { x }", "har x mein xs { x } "},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	l := lexer.New("har (1 mein xs) { x }")
+	p := New(l)
+	p.ParseProgram()
+
+	if len(p.Errors()) == 0 {
+		t.Errorf("expected parser errors for an invalid loop variable, got none")
+	}
+}
+
+func TestBigIntegerLiteralExpression(t *testing.T) {
+	input := "123456789012345678901234567890|"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
+	}
+
+	literal, ok := stmt.Expression.(*ast.BigIntegerLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.BigIntegerLiteral. got=%T", stmt.Expression)
+	}
+	if literal.Value.String() != "123456789012345678901234567890" {
+		t.Errorf("literal.Value not %s. got=%s", "123456789012345678901234567890", literal.Value.String())
+	}
+	if literal.TokenLiteral() != "123456789012345678901234567890" {
+		t.Errorf("literal.TokenLiteral not %s. got=%s", "123456789012345678901234567890",
+			literal.TokenLiteral())
+	}
+}
+
+func TestDecimalLiteralExpression(t *testing.T) {
+	l := lexer.New("12.50d|")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	literal, ok := stmt.Expression.(*ast.DecimalLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.DecimalLiteral. got=%T", stmt.Expression)
+	}
+	if literal.Value != "12.50" {
+		t.Errorf("literal.Value not %q. got=%q", "12.50", literal.Value)
+	}
+	if literal.String() != "12.50d" {
+		t.Errorf("literal.String() not %q. got=%q", "12.50d", literal.String())
+	}
+}
+
+func TestStructStatementParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"dhancha Vyakti { naam, umar }", "dhancha Vyakti { naam, umar }"},
+		{"dhancha Khali {}", "dhancha Khali {  }"},
+		{"ढाँचा Bindu { x, y, }", "dhancha Bindu { x, y }"},
+		{"dhancha Vec { x, y karya __jod__(b) { Vec(yeh.x + b.x, yeh.y + b.y) } }", "dhancha Vec { x, y karya __jod__(b) Vec(((yeh.x) + (b.x)), ((yeh.y) + (b.y))) }"},
+		{"dhancha Vec { x, y| karya lambai() { 0 } }", "dhancha Vec { x, y karya lambai() 0 }"},
+		{"p.naam", "(p.naam)"},
+		{"a.b.c + 1", "(((a.b).c) + 1)"},
+		{"-p.umar", "(-(p.umar))"},
+		{"p.dost.naam[0]", "(((p.dost).naam)[0])"},
+		{"f(x).naam", "(f(x).naam)"},
+		{"p.naam = \"Asha\"|", "(p.naam) = Asha|"},
+		{"p.dost.umar += 1|", "((p.dost).umar) += 1|"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d",
+				len(program.Statements))
+		}
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestClassStatementParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"varg Khali {}", "varg Khali {  }"},
+		{"varg Janwar { karya naya(naam) { yeh.naam = naam| } }", "varg Janwar { karya naya(naam) (yeh.naam) = naam| }"},
+		{"varg Kutta(Janwar) {\n karya bolo() { mool.bolo() }\n}", "varg Kutta(Janwar) { karya bolo() (mool.bolo)() }"},
+		{"वर्ग Billi(Janwar) { karya naam() { यह.naam } }", "varg Billi(Janwar) { karya naam() (yeh.naam) }"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d",
+				len(program.Statements))
+		}
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestInvalidClassStatements(t *testing.T) {
+	inputs := []string{
+		"varg { }",
+		"varg A(5) { }",
+		"varg A { mana x = 1| }",
+		"varg A { karya () { } }",
+		"varg A { karya f() { } karya f() { } }",
+		"mana yeh = 1|",
+	}
+
+	for _, input := range inputs {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+		}
+	}
+}
+
+func TestEnumStatementParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"ganana Rang { Lal, Hara, Neela }", "ganana Rang { Lal, Hara, Neela }"},
+		{"गणना Disha { Uttar, Dakshin, }", "ganana Disha { Uttar, Dakshin }"},
+		{"milao (r) { Rang.Lal => 1, _ => 2 }", "milao (r) { (Rang.Lal) => 1, _ => 2 }"},
+		{"milao (x + 1) { 1 => { mana y = 2| y }| 2 => 3 }", "milao ((x + 1)) { 1 => mana y = 2|y, 2 => 3 }"},
+		{"मिलाओ (r) { }", "milao (r) {  }"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d",
+				len(program.Statements))
+		}
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestMatchWarnings(t *testing.T) {
+	enum := "ganana Rang { Lal, Hara, Neela }\n"
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{enum + "milao (r) { Rang.Lal => 1, Rang.Hara => 2, Rang.Neela => 3 }", []string{}},
+		{enum + "milao (r) { Rang.Lal => 1, _ => 2 }", []string{}},
+		{enum + "milao (r) { Rang.Lal => 1 }", []string{"milao over Rang does not handle Hara, Neela (line 2, column 1)"}},
+		{enum + "milao (r) { Rang.Lal => 1, 5 => 2 }", []string{}},
+		{"milao (r) { Rang.Lal => 1 }", []string{}},
+		{"milao (r) { Rang.Lal => 1 }\n" + enum, []string{"milao over Rang does not handle Hara, Neela (line 1, column 1)"}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+		checkParserErrors(t, p)
+
+		warnings := p.Warnings()
+		if len(warnings) != len(tt.expected) {
+			t.Errorf("wrong warnings for %q. expected=%q, got=%q", tt.input, tt.expected, warnings)
+			continue
+		}
+		for i, msg := range tt.expected {
+			if warnings[i] != msg {
+				t.Errorf("wrong warning. expected=%q, got=%q", msg, warnings[i])
+			}
+		}
+	}
+}
+
+func TestInvalidEnumAndMatchExpressions(t *testing.T) {
+	inputs := []string{
+		"ganana { Lal }",
+		"ganana Rang { Lal, Lal }",
+		"ganana Rang { Lal Hara }",
+		"milao r { 1 => 2 }",
+		"milao (r) { 1 2 }",
+		"milao (r) { 1 => }",
+	}
+
+	for _, input := range inputs {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+		}
+	}
+}
+
+func TestInvalidStructStatements(t *testing.T) {
+	inputs := []string{
+		"dhancha { naam }",
+		"dhancha Vyakti { naam, naam }",
+		"dhancha Vyakti { naam umar }",
+		"dhancha Vec { x, y karya () { 0 } }",
+		"dhancha Vec { x, y karya f() { 0 } z }",
+		"p.1",
+	}
+
+	for _, input := range inputs {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q, got none", input)
+		}
 	}
 }
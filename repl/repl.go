@@ -22,6 +22,12 @@ func Start(in io.Reader, out io.Writer) {
 		fmt.Printf(PROMPT)
 		scanned := scanner.Scan()
 		if !scanned {
+			// generators still open would otherwise never run their aakhir blocks
+			stdout := []string{}
+			env.CloseGenerators(&stdout)
+			for _, s := range stdout {
+				io.WriteString(out, s)
+			}
 			return
 		}
 
@@ -34,9 +40,11 @@ func Start(in io.Reader, out io.Writer) {
 			PrintParserErrors(out, p.Errors())
 			continue
 		}
+		PrintParserWarnings(out, p.Warnings())
 
 		stdout := []string{}
 		evaluated := evaluator.Eval(program, env, &stdout)
+		env.CloseAbandonedGenerators(&stdout)
 		if evaluated != nil && evaluated.Type() != object.NULL_OBJ {
 			io.WriteString(out, evaluated.Inspect())
 			io.WriteString(out, "\n")
@@ -53,3 +61,9 @@ func PrintParserErrors(out io.Writer, errors []string) {
 		io.WriteString(out, "\t"+msg+"\n")
 	}
 }
+
+func PrintParserWarnings(out io.Writer, warnings []string) {
+	for _, msg := range warnings {
+		io.WriteString(out, "\twarning: "+msg+"\n")
+	}
+}
@@ -5,6 +5,8 @@ type TokenType string
 type Token struct {
 	Type    TokenType
 	Literal string
+	Line    int // 1-based line of the token's first character
+	Column  int // 1-based column (in runes) of the token's first character
 }
 
 const (
@@ -12,11 +14,13 @@ const (
 	EOF     = "EOF"
 
 	// Identifiers + literals
-	IDENT  = "IDENT" // add, foobar, x, y, ...
-	INT    = "ANK"
-	STRING = "AKSHARMALA"
+	IDENT   = "IDENT" // add, foobar, x, y, ...
+	INT     = "ANK"
+	DECIMAL = "DASHAMLAV"
+	STRING  = "AKSHARMALA"
+	CHAR    = "AKSHAR"
 
-	SINGLE_COMMENT = "//"
+	SINGLE_COMMENT = "#"
 	MULTI_COMMENT  = "/*"
 
 	// 1343456
@@ -33,6 +37,14 @@ const (
 	EQ       = "=="
 	NOT_EQ   = "!="
 
+	POWER       = "**"
+	FLOOR_DIV   = "//"
+	AMPERSAND   = "&"
+	CARET       = "^"
+	TILDE       = "~"
+	SHIFT_LEFT  = "<<"
+	SHIFT_RIGHT = ">>"
+
 	//Compound Operators
 	PLUS_EQ     = "+="
 	MINUS_EQ    = "-="
@@ -41,10 +53,24 @@ const (
 	LT_EQ       = "<="
 	GT_EQ       = ">="
 
+	MODULO_EQ      = "%="
+	POWER_EQ       = "**="
+	FLOOR_DIV_EQ   = "//="
+	AMPERSAND_EQ   = "&="
+	CARET_EQ       = "^="
+	SHIFT_LEFT_EQ  = "<<="
+	SHIFT_RIGHT_EQ = ">>="
+
 	// Delimiters
 	COMMA    = ","
+	ELLIPSIS = "..."
+	DOT      = "."
+	RANGE    = ".."
+	RANGE_EX = "..<"
 	TERM     = "|"
+	PIPE     = "|>"
 	COLON    = ":"
+	ARROW    = "=>"
 	LPAREN   = "("
 	RPAREN   = ")"
 	LBRACE   = "{"
@@ -62,6 +88,21 @@ const (
 	ELSE_LATIN   = "varna"
 	RETURN_LATIN = "labh"
 	WHILE_LATIN  = "jabtak"
+	FOR_LATIN    = "har"
+	IN_LATIN     = "mein"
+
+	TRY_LATIN     = "koshish"
+	CATCH_LATIN   = "pakdo"
+	FINALLY_LATIN = "aakhir"
+	THROW_LATIN   = "phenko"
+
+	STRUCT_LATIN = "dhancha"
+	CLASS_LATIN  = "varg"
+	SELF_LATIN   = "yeh"
+	SUPER_LATIN  = "mool"
+	ENUM_LATIN   = "ganana"
+	MATCH_LATIN  = "milao"
+	YIELD_LATIN  = "upaj"
 
 	// DEVANAGIRI
 	// FN_DEVANAGIRI     = "कार्य"
@@ -82,6 +123,21 @@ var keywords_latin = map[string]TokenType{
 	"varna":  ELSE_LATIN,
 	"labh":   RETURN_LATIN,
 	"jabtak": WHILE_LATIN,
+	"har":    FOR_LATIN,
+	"mein":   IN_LATIN,
+
+	"koshish": TRY_LATIN,
+	"pakdo":   CATCH_LATIN,
+	"aakhir":  FINALLY_LATIN,
+	"phenko":  THROW_LATIN,
+
+	"dhancha": STRUCT_LATIN,
+	"varg":    CLASS_LATIN,
+	"yeh":     SELF_LATIN,
+	"mool":    SUPER_LATIN,
+	"ganana":  ENUM_LATIN,
+	"milao":   MATCH_LATIN,
+	"upaj":    YIELD_LATIN,
 }
 
 var keywords_devanagiri = map[string]TokenType{
@@ -93,6 +149,24 @@ var keywords_devanagiri = map[string]TokenType{
 	"वरना":  ELSE_LATIN,
 	"लाभ":   RETURN_LATIN,
 	"जबतक":  WHILE_LATIN,
+	"हर":    FOR_LATIN,
+	"में":   IN_LATIN,
+
+	"कोशिश": TRY_LATIN,
+	"पकड़ो": CATCH_LATIN,
+	"पकड़ो":  CATCH_LATIN, // precomposed ड़
+	"आखिर":  FINALLY_LATIN,
+	"आख़िर": FINALLY_LATIN,
+	"फेंको": THROW_LATIN,
+
+	"ढाँचा": STRUCT_LATIN,
+	"ढांचा": STRUCT_LATIN,
+	"वर्ग":  CLASS_LATIN,
+	"यह":    SELF_LATIN,
+	"मूल":   SUPER_LATIN,
+	"गणना":  ENUM_LATIN,
+	"मिलाओ": MATCH_LATIN,
+	"उपज":   YIELD_LATIN,
 }
 
 var devanagiri_to_latin = map[string]string{
@@ -104,6 +178,24 @@ var devanagiri_to_latin = map[string]string{
 	"वरना":  "varna",
 	"लाभ":   "labh",
 	"जबतक":  "jabtak",
+	"हर":    "har",
+	"में":   "mein",
+
+	"कोशिश": "koshish",
+	"पकड़ो": "pakdo",
+	"पकड़ो":  "pakdo",
+	"आखिर":  "aakhir",
+	"आख़िर": "aakhir",
+	"फेंको": "phenko",
+
+	"ढाँचा": "dhancha",
+	"ढांचा": "dhancha",
+	"वर्ग":  "varg",
+	"यह":    "yeh",
+	"मूल":   "mool",
+	"गणना":  "ganana",
+	"मिलाओ": "milao",
+	"उपज":   "upaj",
 }
 
 func LookupIdent(ident string) TokenType {
@@ -23,13 +23,21 @@ func ScriptMode(prog string) string {
 		}
 		return s
 	}
+	for _, msg := range p.Warnings() {
+		s += "\twarning: " + msg + "\n"
+	}
 
 	stdout := []string{}
-	evaluator.Eval(program, env, &stdout)
+	evaluated := evaluator.Eval(program, env, &stdout)
+	env.CloseGenerators(&stdout)
 	for _, output := range stdout {
 		s += output
 	}
 
+	if evaluated != nil && evaluated.Type() == object.ERROR_OBJ {
+		s += evaluated.Inspect() + "\n"
+	}
+
 	return s
 }
 
@@ -45,9 +53,13 @@ func ReplMode(env *object.Environment, line string) string {
 		}
 		return s
 	}
+	for _, msg := range p.Warnings() {
+		s += "\twarning: " + msg + "\n"
+	}
 
 	stdout := []string{}
 	evaluated := evaluator.Eval(program, env, &stdout)
+	env.CloseAbandonedGenerators(&stdout)
 	if evaluated != nil && evaluated.Type() != object.NULL_OBJ {
 		s += evaluated.Inspect() + "\n"
 	}